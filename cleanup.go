@@ -0,0 +1,83 @@
+package gobdd
+
+import (
+	"fmt"
+	"sync"
+)
+
+// cleanupKey is used to store cleanup functions of the running scenario, rule, feature or suite
+type cleanupKey struct{}
+
+// cleanups holds cleanup functions in the order of registration
+type cleanups struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+// Cleanup registers the function to be called when the scenario finishes, after all the after scenario hooks.
+// Cleanup functions are called in the last added, first called order, like with testing.T.Cleanup.
+// Cleanup functions registered in suite, feature or rule hooks are called when the suite, the feature
+// or the rule finishes. Cleanup panics when the context doesn't belong to the running suite.
+func (ctx Context) Cleanup(f func()) {
+	c, ok := ctx.values[cleanupKey{}].(*cleanups)
+	if !ok {
+		panic("cleanup functions can be registered only while running the suite")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.funcs = append(c.funcs, f)
+}
+
+// withCleanups makes the context collect its own cleanup functions
+func withCleanups(ctx Context) Context {
+	ctx.Set(cleanupKey{}, &cleanups{})
+
+	return ctx
+}
+
+// runCleanups calls cleanup functions of the context in the reverse order. A panicking function fails the test,
+// the rest of functions is called anyway. The error of the first failed function is returned.
+func runCleanups(ctx Context, t TestingT) error {
+	c, ok := ctx.values[cleanupKey{}].(*cleanups)
+	if !ok {
+		return nil
+	}
+
+	var firstErr error
+
+	for {
+		c.mu.Lock()
+		if len(c.funcs) == 0 {
+			c.mu.Unlock()
+
+			return firstErr
+		}
+
+		f := c.funcs[len(c.funcs)-1]
+		c.funcs = c.funcs[:len(c.funcs)-1]
+		c.mu.Unlock()
+
+		if err := callCleanup(f); err != nil {
+			t.Error(err.Error())
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+}
+
+// callCleanup calls the cleanup function and converts its panic to an error
+func callCleanup(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("the cleanup function failed: %+v", r)
+		}
+	}()
+
+	f()
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package gobdd
+
+import (
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanup(t *testing.T) {
+	calls := []string{}
+	cleanup := func(ctx Context, name string) {
+		ctx.Cleanup(func() {
+			calls = append(calls, name)
+		})
+	}
+
+	suite := NewSuite(t, WithFeaturesPath("features/background.feature"),
+		WithBeforeSuite(func(ctx Context) {
+			cleanup(ctx, "suite")
+		}),
+		WithBeforeFeature(func(ctx Context, _ *msgs.Feature) {
+			cleanup(ctx, "feature")
+		}),
+		WithBeforeScenario(func(ctx Context) {
+			cleanup(ctx, "before scenario")
+		}),
+		WithAfterScenario(func(ctx Context) {
+			calls = append(calls, "after scenario")
+		}),
+	)
+	suite.AddStep(`I add (\d+) and (\d+)`, func(t StepTest, ctx Context, var1, var2 int) {
+		add(t, ctx, var1, var2)
+		cleanup(ctx, "step")
+	})
+	suite.AddStep(`the result should equal (\d+)`, check)
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+	suite.Run()
+
+	require.Equal(t, []string{
+		"after scenario", "step", "before scenario",
+		"after scenario", "step", "before scenario",
+		"feature",
+		"suite",
+	}, calls)
+}
+
+func TestCleanup_OutsideOfSuite(t *testing.T) {
+	require.PanicsWithValue(t, "cleanup functions can be registered only while running the suite", func() {
+		NewContext().Cleanup(func() {})
+	})
+}
+
+func TestRunCleanups(t *testing.T) {
+	tester := &mockTester{}
+	ctx := withCleanups(NewContext())
+	calls := []int{}
+
+	ctx.Cleanup(func() { calls = append(calls, 1) })
+	ctx.Cleanup(func() { panic("cannot remove the file") })
+	ctx.Cleanup(func() { calls = append(calls, 3) })
+
+	err := runCleanups(ctx, tester)
+
+	require.EqualError(t, err, "the cleanup function failed: cannot remove the file")
+	require.Equal(t, []int{3, 1}, calls)
+	require.Equal(t, []string{"the cleanup function failed: cannot remove the file"}, tester.errors)
+	require.NoError(t, runCleanups(ctx, tester))
+}
+
+func TestTeardown(t *testing.T) {
+	testCases := map[string]struct {
+		step       func(t StepTest)
+		beforeStep func(t StepTest)
+	}{
+		"panicking step": {
+			step: func(StepTest) { panic("the step panicked") },
+		},
+		"fatal step": {
+			step: func(t StepTest) { t.Fatal("the step failed") },
+		},
+		"failed before step hook": {
+			beforeStep: func(t StepTest) { t.Fatal("the hook failed") },
+		},
+		"panicking before step hook": {
+			beforeStep: func(StepTest) { panic("the hook panicked") },
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			attempt, afterSteps, afterScenarios, cleanups := 0, 0, 0, 0
+
+			suite := NewSuite(t, WithFeaturesPath("features/hooks.feature"), WithTags("@db"), WithRetries(1),
+				WithBeforeScenario(func(Context) {
+					attempt++
+				}),
+				WithBeforeStepT(func(t StepTest, ctx Context) {
+					ctx.Cleanup(func() {
+						cleanups++
+					})
+
+					if attempt == 1 && testCase.beforeStep != nil {
+						testCase.beforeStep(t)
+					}
+				}),
+				WithAfterStep(func(Context) {
+					afterSteps++
+				}),
+				WithAfterScenarioT(func(t StepTest, ctx Context, result HookResult) {
+					afterScenarios++
+					require.Equal(t, attempt, afterScenarios)
+					require.Equal(t, attempt == 1, result.Err != nil)
+				}),
+			)
+			suite.AddStep(`the database is connected`, func(t StepTest, ctx Context) {
+				if attempt == 1 && testCase.step != nil {
+					testCase.step(t)
+				}
+			})
+			suite.Run()
+
+			require.Equal(t, 2, attempt)
+			require.Equal(t, 2, afterSteps)
+			require.Equal(t, 2, afterScenarios)
+			require.Equal(t, 2, cleanups)
+		})
+	}
+}
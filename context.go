@@ -2,6 +2,7 @@ package gobdd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -65,6 +66,19 @@ func (ctx Context) GetAs(key interface{}, dest interface{}) error {
 	return json.Unmarshal(d, dest)
 }
 
+// Attach attaches data, like a screenshot or a log, to the currently executed step.
+// Attachments are included in reports, for example in the Cucumber JSON report.
+func (ctx Context) Attach(data []byte, mediaType string) error {
+	attach, ok := ctx.values[attachKey{}].(func(data []byte, mediaType string))
+	if !ok {
+		return errors.New("attachments can be added only while executing a step")
+	}
+
+	attach(data, mediaType)
+
+	return nil
+}
+
 // It is a shortcut for getting the value already casted as error.
 func (ctx Context) GetError(key interface{}, defaultValue ...error) (error, error) {
 	if _, ok := ctx.values[key]; !ok {
@@ -0,0 +1,478 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parameterType describes a parameter type, like {int}, used in step expressions
+type parameterType struct {
+	name    string
+	regexps []string
+	// transform converts the captured text to the value passed to the step function,
+	// the text itself is passed when it's nil
+	transform func(ctx Context, text string) (interface{}, error)
+	// goType is the type returned by the transformer of the parameter type added with AddParameterType
+	goType reflect.Type
+	// cucumberExpressionsOnly is true for built-in parameter types which are not replaced in regular expressions
+	cucumberExpressionsOnly bool
+}
+
+// parameterTypeName matches names of parameter types, like {color}, which can be used in Cucumber Expressions
+var parameterTypeName = regexp.MustCompile(`^\{[^{}()\\/\s]+\}$`)
+
+// builtinParameterTypes are available in every suite.
+// See https://github.com/cucumber/cucumber-expressions#parameter-types
+func builtinParameterTypes() []*parameterType {
+	// values are captured by groups, so the regexps can be inserted into regular expressions as they are
+	integer := []string{`(-?\d+)`}
+	float := []string{`([-+]?\d*\.?\d+)`}
+
+	types := []*parameterType{
+		{name: "int", regexps: integer, transform: func(_ Context, text string) (interface{}, error) {
+			return strconv.Atoi(text)
+		}},
+		{name: "float", regexps: float, transform: func(_ Context, text string) (interface{}, error) {
+			v, err := strconv.ParseFloat(text, 32) // nolint:mnd
+			return float32(v), err
+		}},
+		{name: "word", regexps: []string{`([^\s]+)`}},
+		{name: "text", regexps: []string{`"([^"\\]*(?:\\.[^"\\]*)*)"`, `'([^'\\]*(?:\\.[^'\\]*)*)'`}},
+	}
+
+	cucumberExpressionsOnly := []*parameterType{
+		{name: "string", regexps: []string{`"([^"\\]*(?:\\.[^"\\]*)*)"`, `'([^'\\]*(?:\\.[^'\\]*)*)'`},
+			transform: func(_ Context, text string) (interface{}, error) {
+				return strings.NewReplacer(`\"`, `"`, `\'`, `'`).Replace(text), nil
+			}},
+		{name: "", regexps: []string{`.*`}},
+		{name: "double", regexps: float, transform: func(_ Context, text string) (interface{}, error) {
+			return strconv.ParseFloat(text, 64) // nolint:mnd
+		}},
+		{name: "bigdecimal", regexps: float, transform: func(_ Context, text string) (interface{}, error) {
+			v, ok := new(big.Float).SetString(text)
+			if !ok {
+				return nil, fmt.Errorf("%q is not a valid decimal", text)
+			}
+
+			return v, nil
+		}},
+		{name: "long", regexps: integer, transform: func(_ Context, text string) (interface{}, error) {
+			return strconv.ParseInt(text, 10, 64) // nolint:mnd
+		}},
+		{name: "short", regexps: integer, transform: func(_ Context, text string) (interface{}, error) {
+			v, err := strconv.ParseInt(text, 10, 16) // nolint:mnd
+			return int16(v), err
+		}},
+		{name: "byte", regexps: integer, transform: func(_ Context, text string) (interface{}, error) {
+			v, err := strconv.ParseInt(text, 10, 8) // nolint:mnd
+			return int8(v), err
+		}},
+		{name: "biginteger", regexps: integer, transform: func(_ Context, text string) (interface{}, error) {
+			v, ok := new(big.Int).SetString(text, 10) // nolint:mnd
+			if !ok {
+				return nil, fmt.Errorf("%q is not a valid integer", text)
+			}
+
+			return v, nil
+		}},
+	}
+
+	for _, t := range cucumberExpressionsOnly {
+		t.cucumberExpressionsOnly = true
+	}
+
+	return append(types, cucumberExpressionsOnly...)
+}
+
+// expressionParameter is a parameter of the compiled Cucumber Expression
+type expressionParameter struct {
+	parameterType *parameterType
+	// groups holds indexes of groups capturing the value, one for every regexp of the parameter type
+	groups []int
+}
+
+// parameterValue is the value of the parameter captured from the step's text
+type parameterValue struct {
+	parameterType *parameterType
+	text          string
+}
+
+// convert converts the value to the type of the step function's argument. The result of the parameter type's
+// transformer is used if it fits, otherwise the text is converted as for regular expressions.
+// The context is nil in dry run.
+func (v parameterValue) convert(ctx *Context, inType reflect.Type) (reflect.Value, error) {
+	t := v.parameterType
+
+	if t.transform == nil {
+		return paramType(v.text, inType)
+	}
+
+	if err := t.checkType(inType); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if ctx == nil && t.goType != nil {
+		// transformers added with AddParameterType aren't called in dry run, only the type of the value is checked
+		return reflect.Zero(inType), nil
+	}
+
+	var c Context
+	if ctx != nil {
+		c = *ctx
+	}
+
+	value, err := t.transform(c, v.text)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot convert %q to {%s}: %w", v.text, t.name, err)
+	}
+
+	switch {
+	case value == nil && t.goType != nil:
+		return reflect.Zero(inType), nil
+	case value != nil && reflect.TypeOf(value).AssignableTo(inType):
+		return reflect.ValueOf(value), nil
+	default:
+		return paramType(v.text, inType)
+	}
+}
+
+// checkType checks whether values of the parameter type can be passed as the step function's argument
+func (t *parameterType) checkType(inType reflect.Type) error {
+	if t.goType == nil {
+		return checkParamType(inType)
+	}
+
+	if !t.goType.AssignableTo(inType) {
+		return fmt.Errorf("the parameter type {%s} returns %s which cannot be used as %s", t.name, t.goType, inType)
+	}
+
+	return nil
+}
+
+// newParameterType creates the parameter type converting values with the transformer.
+// The transformer is a function accepting the captured text and, optionally, the context as the first argument.
+// It returns the value and an error:
+//
+//	func(value string) (Color, error)
+//	func(ctx gobdd.Context, value string) (*User, error)
+func newParameterType(name string, regexps []string, transformer interface{}) (*parameterType, error) {
+	f := reflect.ValueOf(transformer)
+	if f.Kind() != reflect.Func {
+		return nil, errors.New("the transformer should be a function")
+	}
+
+	ft := f.Type()
+	contextual := ft.NumIn() == 2 // nolint:mnd
+	stringType := reflect.TypeOf("")
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+
+	switch {
+	case ft.NumIn() == 1 && ft.In(0) == stringType:
+	case contextual && ft.In(0) == reflect.TypeOf(Context{}) && ft.In(1) == stringType:
+	default:
+		return nil, errors.New("the transformer should accept a string or the Context and a string")
+	}
+
+	if ft.NumOut() != 2 || ft.Out(1) != errorType { // nolint:mnd
+		return nil, errors.New("the transformer should return a value and an error")
+	}
+
+	for _, r := range regexps {
+		if _, err := regexp.Compile(r); err != nil {
+			return nil, fmt.Errorf("the regular expression %s doesn't compile: %w", r, err)
+		}
+	}
+
+	return &parameterType{
+		name:    name,
+		regexps: regexps,
+		transform: func(ctx Context, text string) (interface{}, error) {
+			in := []reflect.Value{reflect.ValueOf(text)}
+			if contextual {
+				in = append([]reflect.Value{reflect.ValueOf(ctx)}, in...)
+			}
+
+			out := f.Call(in)
+			if err, _ := out[1].Interface().(error); err != nil {
+				return nil, err
+			}
+
+			return out[0].Interface(), nil
+		},
+		goType: ft.Out(0),
+	}, nil
+}
+
+// cucumberExpressionNode is a part of the parsed Cucumber Expression
+type cucumberExpressionNode struct {
+	kind cucumberExpressionNodeKind
+	// text is the unescaped text of text, optional and whitespace nodes or the name of the parameter
+	text string
+}
+
+type cucumberExpressionNodeKind int
+
+const (
+	nodeText cucumberExpressionNodeKind = iota
+	nodeWhitespace
+	nodeOptional
+	nodeParameter
+	nodeAlternation
+)
+
+// compileCucumberExpression compiles the Cucumber Expression to a regular expression matching the whole text.
+// See https://github.com/cucumber/cucumber-expressions
+func compileCucumberExpression(expr string, types map[string]*parameterType) (*regexp.Regexp,
+	[]expressionParameter, error) {
+	nodes, err := parseCucumberExpression(expr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the cucumber expression %q is incorrect: %w", expr, err)
+	}
+
+	c := cucumberExpressionCompiler{types: types}
+
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	// alternations are bound by whitespaces, so the expression is compiled word by word
+	var word []cucumberExpressionNode
+
+	for _, node := range append(nodes, cucumberExpressionNode{kind: nodeWhitespace}) {
+		if node.kind != nodeWhitespace {
+			word = append(word, node)
+
+			continue
+		}
+
+		compiled, err := c.compileWord(word)
+		if err != nil {
+			return nil, nil, fmt.Errorf("the cucumber expression %q is incorrect: %w", expr, err)
+		}
+
+		sb.WriteString(compiled)
+		sb.WriteString(regexp.QuoteMeta(node.text))
+
+		word = nil
+	}
+
+	sb.WriteString("$")
+
+	compiled, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("the cucumber expression %q is incorrect: %w", expr, err)
+	}
+
+	return compiled, c.parameters, nil
+}
+
+type cucumberExpressionCompiler struct {
+	types      map[string]*parameterType
+	parameters []expressionParameter
+	// groups is the number of capturing groups in the regular expression so far
+	groups int
+}
+
+func (c *cucumberExpressionCompiler) compileWord(word []cucumberExpressionNode) (string, error) {
+	alternatives := [][]cucumberExpressionNode{{}}
+
+	for _, node := range word {
+		if node.kind == nodeAlternation {
+			alternatives = append(alternatives, []cucumberExpressionNode{})
+
+			continue
+		}
+
+		alternatives[len(alternatives)-1] = append(alternatives[len(alternatives)-1], node)
+	}
+
+	if len(alternatives) == 1 {
+		return c.compileNodes(word)
+	}
+
+	compiled := make([]string, 0, len(alternatives))
+
+	for _, alternative := range alternatives {
+		onlyOptionals := true
+
+		for _, node := range alternative {
+			if node.kind == nodeParameter {
+				return "", errors.New("an alternative may not contain a parameter type")
+			}
+
+			if node.kind != nodeOptional {
+				onlyOptionals = false
+			}
+		}
+
+		if len(alternative) == 0 {
+			return "", errors.New("an alternative may not be empty")
+		}
+
+		if onlyOptionals {
+			return "", errors.New("an alternative may not exclusively contain optionals")
+		}
+
+		compiledAlternative, err := c.compileNodes(alternative)
+		if err != nil {
+			return "", err
+		}
+
+		compiled = append(compiled, compiledAlternative)
+	}
+
+	return "(?:" + strings.Join(compiled, "|") + ")", nil
+}
+
+func (c *cucumberExpressionCompiler) compileNodes(nodes []cucumberExpressionNode) (string, error) {
+	var sb strings.Builder
+
+	for _, node := range nodes {
+		switch node.kind {
+		case nodeText:
+			sb.WriteString(regexp.QuoteMeta(node.text))
+		case nodeOptional:
+			sb.WriteString("(?:" + regexp.QuoteMeta(node.text) + ")?")
+		case nodeParameter:
+			compiled, err := c.compileParameter(node.text)
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteString(compiled)
+		case nodeWhitespace, nodeAlternation:
+			// handled while compiling words
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func (c *cucumberExpressionCompiler) compileParameter(name string) (string, error) {
+	parameterType, ok := c.types["{"+name+"}"]
+	if !ok {
+		return "", fmt.Errorf("undefined parameter type {%s}", name)
+	}
+
+	parameter := expressionParameter{parameterType: parameterType}
+	alternatives := make([]string, 0, len(parameterType.regexps))
+
+	for _, r := range parameterType.regexps {
+		compiled, err := regexp.Compile(r)
+		if err != nil {
+			return "", fmt.Errorf("the regular expression of the parameter type {%s} doesn't compile: %w", name, err)
+		}
+
+		parameter.groups = append(parameter.groups, c.groups+1)
+
+		// the value is captured by the first group of the regexp or by the whole regexp if there's no group
+		if compiled.NumSubexp() == 0 {
+			alternatives = append(alternatives, "("+r+")")
+			c.groups++
+		} else {
+			alternatives = append(alternatives, "(?:"+r+")")
+			c.groups += compiled.NumSubexp()
+		}
+	}
+
+	c.parameters = append(c.parameters, parameter)
+
+	return "(?:" + strings.Join(alternatives, "|") + ")", nil
+}
+
+// parseCucumberExpression splits the expression into text, whitespaces, optionals, parameters and alternations
+func parseCucumberExpression(expr string) ([]cucumberExpressionNode, error) {
+	var (
+		nodes   []cucumberExpressionNode
+		current strings.Builder
+		// open is '(' or '{' while parsing an optional or a parameter
+		open    rune
+		escaped bool
+	)
+
+	flush := func(kind cucumberExpressionNodeKind) {
+		if current.Len() > 0 || kind == nodeParameter {
+			nodes = append(nodes, cucumberExpressionNode{kind: kind, text: current.String()})
+		}
+
+		current.Reset()
+	}
+
+	for _, r := range expr {
+		switch {
+		case escaped:
+			if !strings.ContainsRune(`(){}/\`, r) && !unicode.IsSpace(r) {
+				return nil, fmt.Errorf("only the characters '(', ')', '{', '}', '/', '\\' and whitespaces "+
+					"can be escaped, got '%c'", r)
+			}
+
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case open == '(':
+			switch r {
+			case ')':
+				if current.Len() == 0 {
+					return nil, errors.New("an optional must contain some text")
+				}
+
+				flush(nodeOptional)
+				open = 0
+			case '(':
+				return nil, errors.New("an optional may not contain an other optional")
+			case '{':
+				return nil, errors.New("an optional may not contain a parameter type")
+			default:
+				current.WriteRune(r)
+			}
+		case open == '{':
+			switch r {
+			case '}':
+				flush(nodeParameter)
+				open = 0
+			case '{', '(', ')', '/':
+				return nil, fmt.Errorf("a parameter type name may not contain '%c'", r)
+			default:
+				current.WriteRune(r)
+			}
+		case r == '(' || r == '{':
+			flush(nodeText)
+			open = r
+		case r == '/':
+			flush(nodeText)
+			nodes = append(nodes, cucumberExpressionNode{kind: nodeAlternation})
+		case unicode.IsSpace(r):
+			if len(nodes) > 0 && nodes[len(nodes)-1].kind == nodeWhitespace && current.Len() == 0 {
+				nodes[len(nodes)-1].text += string(r)
+
+				continue
+			}
+
+			flush(nodeText)
+			nodes = append(nodes, cucumberExpressionNode{kind: nodeWhitespace, text: string(r)})
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	switch {
+	case escaped:
+		return nil, errors.New("the expression ends with an unfinished escape sequence")
+	case open == '(':
+		return nil, errors.New("the optional is not closed, use '\\(' to match '(' literally")
+	case open == '{':
+		return nil, errors.New("the parameter type is not closed, use '\\{' to match '{' literally")
+	}
+
+	flush(nodeText)
+
+	return nodes, nil
+}
@@ -0,0 +1,357 @@
+package gobdd
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileCucumberExpression(t *testing.T) {
+	types := map[string]*parameterType{}
+	for _, parameterType := range builtinParameterTypes() {
+		types["{"+parameterType.name+"}"] = parameterType
+	}
+
+	tests := map[string]struct {
+		expr   string
+		text   string
+		values []string
+	}{
+		"plain text":            {expr: "I eat a cucumber", text: "I eat a cucumber", values: []string{}},
+		"optional text present": {expr: "I eat cucumber(s)", text: "I eat cucumbers", values: []string{}},
+		"optional text missing": {expr: "I eat cucumber(s)", text: "I eat cucumber", values: []string{}},
+		"alternative text":      {expr: "in my belly/stomach", text: "in my stomach", values: []string{}},
+		"alternative with optional": {
+			expr: "I eat cucumber(s)/gherkin(s)", text: "I eat gherkins", values: []string{},
+		},
+		"escaped characters": {expr: `a \(b\) \{c\} d\/e \\`, text: `a (b) {c} d/e \`, values: []string{}},
+		"closing brackets": {
+			expr: `I eat \(a lot) \{of} cucumbers`, text: `I eat (a lot) {of} cucumbers`, values: []string{},
+		},
+		"regexp characters": {expr: "it costs $1.5?", text: "it costs $1.5?", values: []string{}},
+		"int":               {expr: "I eat {int} cucumbers", text: "I eat -3 cucumbers", values: []string{"-3"}},
+		"string with double quotes": {
+			expr: "I say {string}", text: `I say "hello 'world'"`, values: []string{`hello 'world'`},
+		},
+		"string with single quotes": {expr: "I say {string}", text: `I say 'hello'`, values: []string{`hello`}},
+		"anonymous": {
+			expr: "I say {} loudly", text: "I say hello world loudly", values: []string{"hello world"},
+		},
+		"many parameters": {
+			expr: "{word} has {int} and {float}", text: "cucumber has 1 and 2.5", values: []string{"cucumber", "1", "2.5"},
+		},
+		"doesn't match a part of the text": {expr: "I eat {int}", text: "I eat 1 cucumber"},
+		"doesn't match missing optional":   {expr: "I eat cucumber(s)", text: "I eat cucumberss"},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			compiled, parameters, err := compileCucumberExpression(test.expr, types)
+			require.NoError(t, err)
+
+			def := stepDef{expr: compiled, parameters: parameters}
+
+			if test.values == nil {
+				require.False(t, compiled.MatchString(test.text))
+
+				return
+			}
+
+			require.True(t, compiled.MatchString(test.text), compiled.String())
+
+			values := []string{}
+			for _, m := range def.matchedGroups(test.text) {
+				values = append(values, m.value)
+			}
+
+			require.Equal(t, test.values, values)
+		})
+	}
+}
+
+func TestCompileCucumberExpression_Errors(t *testing.T) {
+	tests := map[string]string{
+		"I eat {color} cucumbers": "undefined parameter type {color}",
+		"I eat cucumber()":        "an optional must contain some text",
+		"I eat (({int}))":         "an optional may not contain an other optional",
+		"I eat ({int})":           "an optional may not contain a parameter type",
+		"I eat {in(t}":            "a parameter type name may not contain '('",
+		"I eat {int":              "the parameter type is not closed",
+		"I eat (s":                "the optional is not closed",
+		`I eat \d`:                "only the characters",
+		`I eat \`:                 "unfinished escape sequence",
+		"I eat {int}/many":        "an alternative may not contain a parameter type",
+		"I eat /many":             "an alternative may not be empty",
+		"I eat (a)/many":          "an alternative may not exclusively contain optionals",
+	}
+
+	for expr, expected := range tests {
+		expr, expected := expr, expected
+
+		t.Run(expr, func(t *testing.T) {
+			_, _, err := compileCucumberExpression(expr, map[string]*parameterType{
+				"{int}": {name: "int", regexps: []string{`\d+`}},
+			})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), expected)
+		})
+	}
+}
+
+func TestWithCucumberExpressions(t *testing.T) {
+	var (
+		cucumbers  int
+		double     float64
+		long       int64
+		byteValue  int8
+		short      int16
+		bigInteger *big.Int
+		bigDecimal *big.Float
+		str        string
+		anything   string
+	)
+
+	suite := NewSuite(t, WithFeaturesPath("features/cucumber-expressions.feature"), WithCucumberExpressions())
+	suite.AddStep(`I have {int} cucumber(s) in my belly/stomach`, func(_ StepTest, _ Context, count int) {
+		cucumbers += count
+	})
+	suite.AddStep(`I should have {int} cucumber(s)`, func(t StepTest, _ Context, count int) {
+		if cucumbers != count {
+			t.Fatalf("expected %d cucumbers but %d received", count, cucumbers)
+		}
+	})
+	suite.AddStep(`I store {double} as a double and {long} as a long`, func(_ StepTest, _ Context, d float64, l int64) {
+		double, long = d, l
+	})
+	suite.AddStep(`I store {byte} as a byte and {short} as a short`, func(_ StepTest, _ Context, b int8, s int16) {
+		byteValue, short = b, s
+	})
+	suite.AddStep(`I store {biginteger} as a big integer and {bigdecimal} as a big decimal`,
+		func(_ StepTest, _ Context, i *big.Int, d *big.Float) {
+			bigInteger, bigDecimal = i, d
+		})
+	suite.AddStep(`I store the string {string}`, func(_ StepTest, _ Context, s string) {
+		str = s
+	})
+	suite.AddStep(`I store {} \(in parentheses)`, func(_ StepTest, _ Context, s string) {
+		anything = s
+	})
+
+	suite.Run()
+
+	require.Equal(t, 43, cucumbers)
+	require.Equal(t, 2.5, double)
+	require.Equal(t, int64(9223372036854775807), long)
+	require.Equal(t, int8(-128), byteValue)
+	require.Equal(t, int16(32767), short)
+	require.Equal(t, "123456789012345678901234567890", bigInteger.String())
+	require.Equal(t, "0.1", bigDecimal.Text('g', 10))
+	require.Equal(t, `say "hello"`, str)
+	require.Equal(t, "anything", anything)
+}
+
+func TestWithCucumberExpressions_IncorrectExpression(t *testing.T) {
+	tester := &mockTester{}
+	suite := NewSuite(tester, WithCucumberExpressions())
+	suite.AddStep(`I eat {color} cucumbers`, pass)
+
+	require.True(t, suite.hasStepErrors)
+	require.Len(t, tester.errors, 1)
+	require.Contains(t, tester.errors[0], "undefined parameter type {color}")
+}
+
+func TestParameterValue_ConversionError(t *testing.T) {
+	suite := NewSuite(t, WithCucumberExpressions())
+	suite.AddStep(`I eat {byte} cucumbers`, func(_ StepTest, _ Context, _ int8) {})
+
+	def := suite.steps[0]
+	_, err := def.arguments(nil, def.params(&msgs.Step{Text: "I eat 1000 cucumbers"}, nil))
+	require.EqualError(t, err, `cannot convert "1000" to {byte}: strconv.ParseInt: parsing "1000": value out of range`)
+}
+
+type color string
+
+type user struct {
+	name string
+}
+
+type userKey struct{}
+
+func TestAddParameterType(t *testing.T) {
+	var (
+		painter *user
+		paint   color
+		date    time.Time
+	)
+
+	suite := NewSuite(t, WithFeaturesPath("features/custom-parameter-types.feature"), WithCucumberExpressions())
+	suite.AddParameterType(`{color}`, []string{`red|green|blue`}, func(value string) (color, error) {
+		return color(value), nil
+	})
+	suite.AddParameterType(`{date}`, []string{`\d{4}-\d{2}-\d{2}`}, func(value string) (time.Time, error) {
+		return time.Parse("2006-01-02", value)
+	})
+	suite.AddParameterType(`{user}`, []string{`[a-z]+`}, func(ctx Context, name string) (*user, error) {
+		u, err := ctx.Get(userKey{})
+		if err != nil {
+			return nil, err
+		}
+
+		if u.(*user).name != name {
+			return nil, fmt.Errorf("the user %s does not exist", name)
+		}
+
+		return u.(*user), nil
+	})
+	suite.AddStep(`the user {word}`, func(_ StepTest, ctx Context, name string) {
+		ctx.Set(userKey{}, &user{name: name})
+	})
+	suite.AddStep(`{user} paints the fence {color} on {date}`, func(_ StepTest, _ Context, u *user, c color, d time.Time) {
+		painter, paint, date = u, c, d
+	})
+	suite.AddStep(`the fence is {color}`, func(t StepTest, _ Context, c color) {
+		if paint != c {
+			t.Fatalf("expected the fence to be %s but it's %s", c, paint)
+		}
+	})
+
+	suite.Run()
+
+	require.Equal(t, &user{name: "alice"}, painter)
+	require.Equal(t, color("red"), paint)
+	require.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), date)
+}
+
+func TestAddParameterType_Errors(t *testing.T) {
+	tests := map[string]struct {
+		name        string
+		regexps     []string
+		transformer interface{}
+		expected    string
+	}{
+		"not a function": {
+			name: `{color}`, regexps: []string{`red`}, transformer: "red",
+			expected: "the parameter type {color} is incorrect: the transformer should be a function",
+		},
+		"wrong arguments": {
+			name: `{color}`, regexps: []string{`red`}, transformer: func(int) (color, error) { return "", nil },
+			expected: "the parameter type {color} is incorrect: the transformer should accept a string " +
+				"or the Context and a string",
+		},
+		"no error": {
+			name: `{color}`, regexps: []string{`red`}, transformer: func(string) color { return "" },
+			expected: "the parameter type {color} is incorrect: the transformer should return a value and an error",
+		},
+		"incorrect regexp": {
+			name: `{color}`, regexps: []string{`(red`}, transformer: func(string) (color, error) { return "", nil },
+			expected: "the parameter type {color} is incorrect: the regular expression (red doesn't compile",
+		},
+		"name without braces": {
+			name: `color`, regexps: []string{`red`}, transformer: func(string) (color, error) { return "", nil },
+			expected: "the parameter type color is incorrect: the name should be in the {name} format",
+		},
+		"name with a space": {
+			name: `{light color}`, regexps: []string{`red`}, transformer: func(string) (color, error) { return "", nil },
+			expected: "the parameter type {light color} is incorrect: the name should be in the {name} format",
+		},
+		"already defined": {
+			name: `{int}`, regexps: []string{`\d+`}, transformer: func(string) (int, error) { return 0, nil },
+			expected: "the parameter type {int} is already defined",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			tester := &mockTester{}
+			suite := NewSuite(tester, WithCucumberExpressions())
+			suite.AddParameterType(test.name, test.regexps, test.transformer)
+
+			require.Equal(t, 1, tester.fatalCalled)
+			require.True(t, strings.HasPrefix(tester.fatalMessages[0], test.expected), tester.fatalMessages[0])
+		})
+	}
+}
+
+func TestAddParameterType_ConversionErrors(t *testing.T) {
+	suite := NewSuite(t, WithCucumberExpressions())
+	suite.AddParameterType(`{color}`, []string{`[a-z]+`}, func(value string) (color, error) {
+		if value != "red" {
+			return "", fmt.Errorf("unknown color %s", value)
+		}
+
+		return color(value), nil
+	})
+	suite.AddParameterType(`{user}`, []string{`[a-z]+`}, func(_ Context, name string) (*user, error) {
+		return nil, fmt.Errorf("the user %s does not exist", name)
+	})
+	suite.AddStep(`the fence is {color}`, func(StepTest, Context, color) {})
+	suite.AddStep(`{user} paints`, func(StepTest, Context, *user) {})
+
+	tests := map[string]struct {
+		text     string
+		ctx      *Context
+		expected string
+	}{
+		"transformer fails": {
+			text: "the fence is pink", ctx: &Context{}, expected: `cannot convert "pink" to {color}: unknown color pink`,
+		},
+		"transformer with the context fails": {
+			text: "bob paints", ctx: &Context{}, expected: `cannot convert "bob" to {user}: the user bob does not exist`,
+		},
+		"transformer isn't called in dry run":                  {text: "the fence is pink"},
+		"transformer with the context isn't called in dry run": {text: "bob paints"},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			def, _, err := suite.findStepDef(test.text)
+			require.NoError(t, err)
+
+			_, err = def.arguments(test.ctx, def.params(&msgs.Step{Text: test.text}, nil))
+			if test.expected == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestAddParameterType_WithoutCucumberExpressions(t *testing.T) {
+	tester := &mockTester{}
+	suite := NewSuite(tester)
+	suite.AddParameterType(`{color}`, []string{`red|green|blue`}, func(value string) (color, error) {
+		return color(value), nil
+	})
+	suite.AddStep(`the fence is {color}`, func(StepTest, Context, color) {})
+
+	require.True(t, suite.hasStepErrors)
+	require.Equal(t, []string{"the parameter type {color} of step `the fence is {color}` is supported only by " +
+		"Cucumber Expressions, see WithCucumberExpressions"}, tester.errors)
+}
+
+func TestWithCucumberExpressions_IncorrectArgumentType(t *testing.T) {
+	tester := &mockTester{}
+	suite := NewSuite(tester, WithCucumberExpressions())
+	suite.AddParameterType(`{color}`, []string{`red|green|blue`}, func(value string) (color, error) {
+		return color(value), nil
+	})
+	suite.AddStep(`the wall is {color}`, func(StepTest, Context, string) {})
+
+	require.True(t, suite.hasStepErrors)
+	require.Equal(t, []string{"the step function for step `the wall is {color}` is incorrect: the argument 3 " +
+		"cannot be converted: the parameter type {color} returns gobdd.color which cannot be used as string"}, tester.errors)
+}
@@ -0,0 +1,264 @@
+package gobdd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// WithCucumberJSONReport configures a writer the report in the Cucumber JSON format is written to after running the suite.
+// The format is supported by many tools, like Jenkins Cucumber Reports or Xray.
+func WithCucumberJSONReport(w io.Writer) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.listeners = append(options.listeners, &cucumberJSONFormatter{
+			w:        w,
+			features: map[string]*cucumberJSONFeature{},
+		})
+	}
+}
+
+type cucumberJSONFeature struct {
+	URI         string                 `json:"uri"`
+	ID          string                 `json:"id"`
+	Keyword     string                 `json:"keyword"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Line        int64                  `json:"line"`
+	Tags        []cucumberJSONTag      `json:"tags,omitempty"`
+	Elements    []*cucumberJSONElement `json:"elements"`
+}
+
+type cucumberJSONElement struct {
+	ID          string              `json:"id,omitempty"`
+	Keyword     string              `json:"keyword"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Line        int64               `json:"line"`
+	Type        string              `json:"type"`
+	Tags        []cucumberJSONTag   `json:"tags,omitempty"`
+	Steps       []*cucumberJSONStep `json:"steps"`
+}
+
+type cucumberJSONTag struct {
+	Name string `json:"name"`
+	Line int64  `json:"line"`
+}
+
+type cucumberJSONStep struct {
+	Keyword    string                  `json:"keyword"`
+	Name       string                  `json:"name"`
+	Line       int64                   `json:"line"`
+	DocString  *cucumberJSONDocString  `json:"doc_string,omitempty"`
+	Rows       []cucumberJSONRow       `json:"rows,omitempty"`
+	Match      *cucumberJSONMatch      `json:"match,omitempty"`
+	Result     cucumberJSONResult      `json:"result"`
+	Embeddings []cucumberJSONEmbedding `json:"embeddings,omitempty"`
+}
+
+type cucumberJSONDocString struct {
+	Value       string `json:"value"`
+	ContentType string `json:"content_type,omitempty"`
+	Line        int64  `json:"line"`
+}
+
+type cucumberJSONRow struct {
+	Cells []string `json:"cells"`
+}
+
+type cucumberJSONMatch struct {
+	Location string `json:"location"`
+}
+
+type cucumberJSONResult struct {
+	Status string `json:"status"`
+	// Duration is in nanoseconds
+	Duration     int64  `json:"duration,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+type cucumberJSONEmbedding struct {
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+// cucumberJSONFormatter collects results of test cases and writes the Cucumber JSON report at the end of the run
+type cucumberJSONFormatter struct {
+	baseListener
+
+	w io.Writer
+	// features holds reported features by URI in the order of execution
+	features map[string]*cucumberJSONFeature
+	order    []*cucumberJSONFeature
+}
+
+func (f *cucumberJSONFormatter) testCaseFinished(tc *testCase) {
+	// only the last attempt of the retried test case is reported
+	if tc.willBeRetried {
+		return
+	}
+
+	feature := f.feature(tc)
+
+	scenario := &cucumberJSONElement{
+		ID:          fmt.Sprintf("%s;%s", feature.ID, cucumberJSONID(tc.scenario.Name)),
+		Keyword:     strings.TrimSpace(tc.scenario.Keyword),
+		Name:        tc.scenario.Name,
+		Description: tc.scenario.Description,
+		Line:        tc.scenario.Location.Line,
+		Type:        "scenario",
+		Tags:        cucumberJSONTags(tc.rule, tc.tags),
+		Steps:       []*cucumberJSONStep{},
+	}
+
+	if tc.row != nil {
+		scenario.ID = fmt.Sprintf("%s;%s;%s", scenario.ID, cucumberJSONID(tc.examples.Name), tc.row.Id)
+		scenario.Line = tc.row.Location.Line
+
+		if tc.pickle != nil {
+			scenario.Name = tc.pickle.Name
+		}
+	}
+
+	var background *cucumberJSONElement
+
+	for _, step := range tc.steps {
+		if step.background == nil {
+			scenario.Steps = append(scenario.Steps, cucumberJSONStepFromTestStep(step))
+
+			continue
+		}
+
+		// all backgrounds (from the feature and the rule) are reported as one element
+		if background == nil {
+			background = &cucumberJSONElement{
+				Keyword:     strings.TrimSpace(step.background.Keyword),
+				Name:        step.background.Name,
+				Description: step.background.Description,
+				Line:        step.background.Location.Line,
+				Type:        "background",
+				Steps:       []*cucumberJSONStep{},
+			}
+		}
+
+		background.Steps = append(background.Steps, cucumberJSONStepFromTestStep(step))
+	}
+
+	if background != nil {
+		feature.Elements = append(feature.Elements, background)
+	}
+
+	feature.Elements = append(feature.Elements, scenario)
+}
+
+func (f *cucumberJSONFormatter) runFinished(*testRun) error {
+	features := f.order
+	if features == nil {
+		features = []*cucumberJSONFeature{}
+	}
+
+	data, err := json.MarshalIndent(features, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.w.Write(append(data, '\n'))
+
+	return err
+}
+
+// feature returns the reported feature the test case belongs to
+func (f *cucumberJSONFormatter) feature(tc *testCase) *cucumberJSONFeature {
+	if feature, ok := f.features[tc.uri]; ok {
+		return feature
+	}
+
+	feature := &cucumberJSONFeature{
+		URI:         tc.uri,
+		ID:          cucumberJSONID(tc.feature.Name),
+		Keyword:     tc.feature.Keyword,
+		Name:        tc.feature.Name,
+		Description: tc.feature.Description,
+		Line:        tc.feature.Location.Line,
+		Tags:        cucumberJSONTags(nil, tc.feature.Tags),
+		Elements:    []*cucumberJSONElement{},
+	}
+
+	f.features[tc.uri] = feature
+	f.order = append(f.order, feature)
+
+	return feature
+}
+
+func cucumberJSONStepFromTestStep(step *testStep) *cucumberJSONStep {
+	s := &cucumberJSONStep{
+		Keyword: step.step.Keyword,
+		Name:    step.step.Text,
+		Line:    step.step.Location.Line,
+		Result: cucumberJSONResult{
+			Status:   strings.ToLower(step.result.status.String()),
+			Duration: step.result.duration.Nanoseconds(),
+		},
+	}
+
+	if step.result.err != nil {
+		s.Result.ErrorMessage = step.result.err.Error()
+	}
+
+	if step.def != nil {
+		s.Match = &cucumberJSONMatch{Location: fmt.Sprintf("%s:%d", step.def.file, step.def.line)}
+	}
+
+	if docString := step.step.DocString; docString != nil {
+		s.DocString = &cucumberJSONDocString{
+			Value:       docString.Content,
+			ContentType: docString.MediaType,
+			Line:        docString.Location.Line,
+		}
+	}
+
+	if dataTable := step.step.DataTable; dataTable != nil {
+		for _, row := range dataTable.Rows {
+			cells := make([]string, 0, len(row.Cells))
+			for _, cell := range row.Cells {
+				cells = append(cells, cell.Value)
+			}
+
+			s.Rows = append(s.Rows, cucumberJSONRow{Cells: cells})
+		}
+	}
+
+	for _, a := range step.attachments {
+		s.Embeddings = append(s.Embeddings, cucumberJSONEmbedding{
+			MimeType: a.mediaType,
+			Data:     base64.StdEncoding.EncodeToString(a.data),
+		})
+	}
+
+	return s
+}
+
+// cucumberJSONTags converts tags of the rule (if any) and given tags
+func cucumberJSONTags(rule *msgs.Rule, tags []*msgs.Tag) []cucumberJSONTag {
+	all := []*msgs.Tag{}
+	if rule != nil {
+		all = append(all, rule.Tags...)
+	}
+
+	all = append(all, tags...)
+
+	result := make([]cucumberJSONTag, 0, len(all))
+	for _, tag := range all {
+		result = append(result, cucumberJSONTag{Name: tag.Name, Line: tag.Location.Line})
+	}
+
+	return result
+}
+
+// cucumberJSONID creates an identifier from the name of a feature or a scenario
+func cucumberJSONID(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
@@ -0,0 +1,83 @@
+package gobdd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCucumberJSONReport(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/datatable.feature"), WithCucumberJSONReport(buf))
+	suite.AddStep(`I concat all the columns and row together using {text} to separate the columns`,
+		func(t StepTest, ctx Context, separator string, table msgs.DataTable) {
+			concatTable(t, ctx, separator, table)
+			require.NoError(t, ctx.Attach([]byte("concatenated"), "text/plain"))
+		})
+	suite.AddStep(`the result should equal argument:`, checkt)
+
+	suite.Run()
+
+	features := []*cucumberJSONFeature{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &features))
+	require.Len(t, features, 1)
+
+	feature := features[0]
+	require.Equal(t, "features/datatable.feature", feature.URI)
+	require.Equal(t, "Feature", feature.Keyword)
+	require.Equal(t, "datatable-feature", feature.ID)
+	require.Equal(t, int64(1), feature.Line)
+	require.Len(t, feature.Elements, 1)
+
+	scenario := feature.Elements[0]
+	require.Equal(t, "scenario", scenario.Type)
+	require.Equal(t, "datatable-feature;compare-text-with-datatable", scenario.ID)
+	require.Equal(t, int64(2), scenario.Line)
+	require.Len(t, scenario.Steps, 2)
+
+	when := scenario.Steps[0]
+	require.Equal(t, "When ", when.Keyword)
+	require.Equal(t, "passed", when.Result.Status)
+	require.NotNil(t, when.Match)
+	require.Len(t, when.Rows, 3)
+	require.Equal(t, []string{"r1c1", "r1c2", "r1c3"}, when.Rows[0].Cells)
+	require.Equal(t, []cucumberJSONEmbedding{{MimeType: "text/plain", Data: "Y29uY2F0ZW5hdGVk"}}, when.Embeddings)
+
+	then := scenario.Steps[1]
+	require.NotNil(t, then.DocString)
+	require.Equal(t, int64(8), then.DocString.Line)
+	require.Equal(t, "r1c1 - r1c2 - r1c3\nr2c1 - r2c2 - r2c3\nr3c1 - r3c2 - r3c3", then.DocString.Value)
+}
+
+func TestWithCucumberJSONReport_Background(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/background.feature"), WithCucumberJSONReport(buf))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	features := []*cucumberJSONFeature{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &features))
+	require.Len(t, features, 1)
+
+	types := []string{}
+	steps := []int{}
+
+	for _, element := range features[0].Elements {
+		types = append(types, element.Type)
+		steps = append(steps, len(element.Steps))
+	}
+
+	require.Equal(t, []string{"background", "scenario", "background", "scenario"}, types)
+	require.Equal(t, []int{1, 1, 2, 2}, steps)
+}
+
+func TestAttachOutsideOfStep(t *testing.T) {
+	require.Error(t, NewContext().Attach([]byte("data"), "text/plain"))
+}
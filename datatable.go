@@ -0,0 +1,286 @@
+package gobdd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// checkDataTableType checks whether data tables can be bound to the type. Supported types are:
+//
+//	[][]T                - all the rows
+//	[]map[string]T       - rows following the header, keyed by the column
+//	[]MyStruct           - rows following the header, columns are bound to fields
+//	map[string]T         - a table with two columns, keys in the first one
+//	MyStruct             - a header and a single row or a table with two columns, field names in the first one
+//
+// T is any type the text can be converted to, see checkTextType.
+func checkDataTableType(inType reflect.Type) error {
+	switch inType.Kind() { // nolint:exhaustive // other kinds can't be bound to data tables
+	case reflect.Slice:
+		elem := inType.Elem()
+
+		switch {
+		case elem.Kind() == reflect.Slice && checkTextType(elem.Elem()) == nil:
+			return nil
+		case elem.Kind() == reflect.Map:
+			return checkDataTableMapType(elem)
+		case elem.Kind() == reflect.Struct:
+			return checkDataTableStructType(elem)
+		case elem.Kind() == reflect.Ptr && elem.Elem().Kind() == reflect.Struct:
+			return checkDataTableStructType(elem.Elem())
+		}
+
+		return fmt.Errorf("the slice argument type %s is not supported", inType)
+	case reflect.Map:
+		return checkDataTableMapType(inType)
+	case reflect.Struct:
+		return checkDataTableStructType(inType)
+	default:
+		return fmt.Errorf("the type %s is not supported", inType)
+	}
+}
+
+func checkDataTableMapType(inType reflect.Type) error {
+	if inType.Key().Kind() != reflect.String || checkTextType(inType.Elem()) != nil {
+		return fmt.Errorf("the map argument type %s is not supported", inType)
+	}
+
+	return nil
+}
+
+func checkDataTableStructType(inType reflect.Type) error {
+	fields := tableFields(inType)
+	if len(fields) == 0 {
+		return fmt.Errorf("the struct argument type %s is not supported", inType)
+	}
+
+	for _, field := range fields {
+		if err := checkTextType(inType.Field(field).Type); err != nil {
+			return fmt.Errorf("the struct argument type %s is not supported: the field %s: %w",
+				inType, inType.Field(field).Name, err)
+		}
+	}
+
+	return nil
+}
+
+// tableFields maps names of columns to indexes of exported fields of the struct. The column's name is taken from
+// the `table` tag or the name of the field, fields tagged with `table:"-"` are omitted.
+func tableFields(inType reflect.Type) map[string]int {
+	fields := map[string]int{}
+
+	for i := 0; i < inType.NumField(); i++ {
+		field := inType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Tag.Get("table")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[columnKey(name)] = i
+	}
+
+	return fields
+}
+
+// columnKey normalizes names of columns, so the column "First name" matches the field FirstName
+func columnKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name))
+}
+
+// bindDataTable creates a value of the given type from the data table, see checkDataTableType
+func bindDataTable(table msgs.DataTable, inType reflect.Type) (reflect.Value, error) {
+	if err := checkDataTableType(inType); err != nil {
+		return reflect.Value{}, fmt.Errorf("the data table cannot be bound to %s: %w", inType, err)
+	}
+
+	rows := tableRows(table)
+
+	switch inType.Kind() { // nolint:exhaustive // other kinds are rejected by checkDataTableType
+	case reflect.Map:
+		return bindVerticalMap(rows, inType)
+	case reflect.Struct:
+		return bindStruct(rows, inType)
+	}
+
+	if inType.Elem().Kind() == reflect.Slice {
+		return bindRows(rows, inType)
+	}
+
+	if len(rows) == 0 {
+		return reflect.MakeSlice(inType, 0, 0), nil
+	}
+
+	v := reflect.MakeSlice(inType, 0, len(rows)-1)
+
+	for i := 1; i < len(rows); i++ {
+		var (
+			row reflect.Value
+			err error
+		)
+
+		switch elem := inType.Elem(); {
+		case elem.Kind() == reflect.Map:
+			row, err = bindMap(rows[0], rows[i], i, elem)
+		case elem.Kind() == reflect.Ptr:
+			row, err = bindRow(rows[0], rows[i], i, elem.Elem())
+			if err == nil {
+				ptr := reflect.New(elem.Elem())
+				ptr.Elem().Set(row)
+				row = ptr
+			}
+		default:
+			row, err = bindRow(rows[0], rows[i], i, elem)
+		}
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v = reflect.Append(v, row)
+	}
+
+	return v, nil
+}
+
+// bindRows converts every cell of the table, including the header
+func bindRows(rows [][]string, inType reflect.Type) (reflect.Value, error) {
+	v := reflect.MakeSlice(inType, 0, len(rows))
+
+	for i, cells := range rows {
+		row := reflect.MakeSlice(inType.Elem(), 0, len(cells))
+
+		for j, cell := range cells {
+			value, err := convertText(cell, inType.Elem().Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("the data table row %d, column %d: %w", i+1, j+1, err)
+			}
+
+			row = reflect.Append(row, value)
+		}
+
+		v = reflect.Append(v, row)
+	}
+
+	return v, nil
+}
+
+// bindMap converts the row to a map keyed by columns of the header
+func bindMap(header, cells []string, row int, inType reflect.Type) (reflect.Value, error) {
+	if len(cells) != len(header) {
+		return reflect.Value{}, fmt.Errorf("the data table row %d has %d columns but the header has %d",
+			row+1, len(cells), len(header))
+	}
+
+	v := reflect.MakeMapWithSize(inType, len(header))
+
+	for i, column := range header {
+		value, err := convertText(cells[i], inType.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("the data table row %d, column %q: %w", row+1, column, err)
+		}
+
+		v.SetMapIndex(reflect.ValueOf(column).Convert(inType.Key()), value)
+	}
+
+	return v, nil
+}
+
+// bindVerticalMap converts a table with two columns to a map, keys are in the first column
+func bindVerticalMap(rows [][]string, inType reflect.Type) (reflect.Value, error) {
+	v := reflect.MakeMapWithSize(inType, len(rows))
+
+	for i, cells := range rows {
+		if len(cells) != 2 { // nolint:mnd
+			return reflect.Value{}, fmt.Errorf("the data table row %d has %d columns but %s needs 2",
+				i+1, len(cells), inType)
+		}
+
+		value, err := convertText(cells[1], inType.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("the data table row %d, column %q: %w", i+1, cells[0], err)
+		}
+
+		v.SetMapIndex(reflect.ValueOf(cells[0]).Convert(inType.Key()), value)
+	}
+
+	return v, nil
+}
+
+// bindStruct binds the table to a struct. The table is either vertical, with names of fields in the first column
+// and values in the second one, or horizontal, with the header and a single row.
+func bindStruct(rows [][]string, inType reflect.Type) (reflect.Value, error) {
+	fields := tableFields(inType)
+	vertical := len(rows) > 0
+
+	for _, cells := range rows {
+		if len(cells) != 2 { // nolint:mnd
+			vertical = false
+
+			break
+		}
+
+		if _, ok := fields[columnKey(cells[0])]; !ok {
+			vertical = false
+		}
+	}
+
+	if !vertical {
+		if len(rows) != 2 { // nolint:mnd
+			return reflect.Value{}, fmt.Errorf("the data table should have two columns or a header and a single row "+
+				"to be bound to %s, but it has %d rows", inType, len(rows))
+		}
+
+		return bindRow(rows[0], rows[1], 1, inType)
+	}
+
+	v := reflect.New(inType).Elem()
+
+	for i, cells := range rows {
+		value, err := convertText(cells[1], inType.Field(fields[columnKey(cells[0])]).Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("the data table row %d, column %q: %w", i+1, cells[0], err)
+		}
+
+		v.Field(fields[columnKey(cells[0])]).Set(value)
+	}
+
+	return v, nil
+}
+
+// bindRow binds cells to fields of the struct matching columns of the header
+func bindRow(header, cells []string, row int, inType reflect.Type) (reflect.Value, error) {
+	if len(cells) != len(header) {
+		return reflect.Value{}, fmt.Errorf("the data table row %d has %d columns but the header has %d",
+			row+1, len(cells), len(header))
+	}
+
+	fields := tableFields(inType)
+	v := reflect.New(inType).Elem()
+
+	for i, column := range header {
+		field, ok := fields[columnKey(column)]
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("the data table column %q doesn't match any field of %s", column, inType)
+		}
+
+		value, err := convertText(cells[i], inType.Field(field).Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("the data table row %d, column %q: %w", row+1, column, err)
+		}
+
+		v.Field(field).Set(value)
+	}
+
+	return v, nil
+}
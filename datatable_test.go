@@ -0,0 +1,190 @@
+package gobdd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+type tableUser struct {
+	Name    string
+	Age     int
+	Email   string `table:"Email address"`
+	Ignored string `table:"-"`
+}
+
+func newDataTable(rows ...[]string) msgs.DataTable {
+	table := msgs.DataTable{}
+
+	for _, cells := range rows {
+		row := &msgs.TableRow{}
+		for _, cell := range cells {
+			row.Cells = append(row.Cells, &msgs.TableCell{Value: cell})
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+func TestDataTableBinding(t *testing.T) {
+	var (
+		users    []tableUser
+		settings map[string]string
+		admin    *tableUser
+		matrix   [][]int
+	)
+
+	suite := NewSuite(t, WithFeaturesPath("features/datatable-binding.feature"))
+	suite.AddStep(`the users:`, func(_ StepTest, _ Context, u []tableUser) {
+		users = u
+	})
+	suite.AddStep(`the settings:`, func(_ StepTest, _ Context, s map[string]string) {
+		settings = s
+	})
+	suite.AddStep(`the admin:`, func(_ StepTest, _ Context, u tableUser) {
+		admin = &u
+	})
+	suite.AddStep(`the matrix is:`, func(_ StepTest, _ Context, m [][]int) {
+		matrix = m
+	})
+
+	suite.Run()
+
+	require.Equal(t, []tableUser{
+		{Name: "alice", Age: 30, Email: "alice@example.com"},
+		{Name: "bob", Age: 25, Email: "bob@example.com"},
+	}, users)
+	require.Equal(t, map[string]string{"theme": "dark", "language": "en"}, settings)
+	require.Equal(t, &tableUser{Name: "carol", Age: 41}, admin)
+	require.Equal(t, [][]int{{1, 2}, {3, 4}}, matrix)
+}
+
+func TestBindDataTable(t *testing.T) {
+	type event struct {
+		Name     string
+		Duration time.Duration
+		At       *time.Time
+	}
+
+	at := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		table    msgs.DataTable
+		expected interface{}
+	}{
+		"rows of strings": {
+			table:    newDataTable([]string{"a", "b"}, []string{"c", "d"}),
+			expected: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		"maps": {
+			table:    newDataTable([]string{"name", "age"}, []string{"alice", "30"}, []string{"bob", "25"}),
+			expected: []map[string]string{{"name": "alice", "age": "30"}, {"name": "bob", "age": "25"}},
+		},
+		"maps of ints": {
+			table:    newDataTable([]string{"x", "y"}, []string{"1", "2"}),
+			expected: []map[string]int{{"x": 1, "y": 2}},
+		},
+		"vertical map": {
+			table:    newDataTable([]string{"timeout", "1s"}, []string{"delay", "1m"}),
+			expected: map[string]time.Duration{"timeout": time.Second, "delay": time.Minute},
+		},
+		"structs": {
+			table:    newDataTable([]string{"name", "duration", "at"}, []string{"deploy", "5m", "2024-03-15T10:00:00Z"}),
+			expected: []event{{Name: "deploy", Duration: 5 * time.Minute, At: &at}},
+		},
+		"pointers to structs": {
+			table:    newDataTable([]string{"Name"}, []string{"deploy"}, []string{"release"}),
+			expected: []*event{{Name: "deploy"}, {Name: "release"}},
+		},
+		"empty slice": {
+			table:    newDataTable([]string{"name"}),
+			expected: []event{},
+		},
+		"horizontal struct": {
+			table:    newDataTable([]string{"first name", "age"}, []string{"alice", "30"}),
+			expected: struct{ FirstName, Age string }{FirstName: "alice", Age: "30"},
+		},
+		"vertical struct": {
+			table:    newDataTable([]string{"first_name", "alice"}, []string{"age", "30"}),
+			expected: struct{ FirstName, Age string }{FirstName: "alice", Age: "30"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			v, err := paramType(testCase.table, reflect.TypeOf(testCase.expected))
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, v.Interface())
+		})
+	}
+}
+
+func TestBindDataTable_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		table    msgs.DataTable
+		inType   reflect.Type
+		expected string
+	}{
+		"wrong cell in a struct": {
+			table:  newDataTable([]string{"name", "age"}, []string{"alice", "30"}, []string{"bob", "old"}),
+			inType: reflect.TypeOf([]tableUser{}),
+			expected: `the data table row 3, column "age": cannot convert "old" to int: ` +
+				`strconv.ParseInt: parsing "old": invalid syntax`,
+		},
+		"wrong cell in a map": {
+			table:  newDataTable([]string{"x"}, []string{"a"}),
+			inType: reflect.TypeOf([]map[string]int{}),
+			expected: `the data table row 2, column "x": cannot convert "a" to int: ` +
+				`strconv.ParseInt: parsing "a": invalid syntax`,
+		},
+		"wrong cell in rows": {
+			table:  newDataTable([]string{"1", "a"}),
+			inType: reflect.TypeOf([][]int{}),
+			expected: `the data table row 1, column 2: cannot convert "a" to int: ` +
+				`strconv.ParseInt: parsing "a": invalid syntax`,
+		},
+		"unknown column": {
+			table:    newDataTable([]string{"name", "phone"}, []string{"alice", "123"}),
+			inType:   reflect.TypeOf([]tableUser{}),
+			expected: `the data table column "phone" doesn't match any field of gobdd.tableUser`,
+		},
+		"missing cells": {
+			table:    newDataTable([]string{"name", "age"}, []string{"alice"}),
+			inType:   reflect.TypeOf([]map[string]string{}),
+			expected: "the data table row 2 has 1 columns but the header has 2",
+		},
+		"too many columns for a map": {
+			table:    newDataTable([]string{"a", "b", "c"}),
+			inType:   reflect.TypeOf(map[string]string{}),
+			expected: "the data table row 1 has 3 columns but map[string]string needs 2",
+		},
+		"too many rows for a struct": {
+			table:  newDataTable([]string{"name", "age"}, []string{"alice", "30"}, []string{"bob", "25"}),
+			inType: reflect.TypeOf(tableUser{}),
+			expected: "the data table should have two columns or a header and a single row to be bound to " +
+				"gobdd.tableUser, but it has 3 rows",
+		},
+		"unsupported field": {
+			table:  newDataTable([]string{"name"}, []string{"alice"}),
+			inType: reflect.TypeOf([]struct{ Name chan int }{}),
+			expected: "the struct argument type struct { Name chan int } is not supported: " +
+				"the field Name: the type chan int is not supported",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			_, err := paramType(testCase.table, testCase.inType)
+			require.EqualError(t, err, testCase.expected)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package gobdd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocString is the doc string of the step together with its media type, like "json" in """json.
+// Step functions can accept it as the last argument instead of the content only.
+type DocString struct {
+	Content   string
+	MediaType string
+}
+
+var docStringType = reflect.TypeOf(DocString{})
+
+// defaultDocStringDecoders are used to decode doc strings unless they're overridden with WithDocStringDecoder
+func defaultDocStringDecoders() map[string]func(data []byte, v interface{}) error {
+	return map[string]func(data []byte, v interface{}) error{
+		"json":             json.Unmarshal,
+		"application/json": json.Unmarshal,
+		"yaml":             yaml.Unmarshal,
+		"yml":              yaml.Unmarshal,
+		"application/yaml": yaml.Unmarshal,
+		"text/yaml":        yaml.Unmarshal,
+	}
+}
+
+// WithDocStringDecoder configures the function decoding doc strings with the media type to arguments
+// of step functions, like structs or maps. Decoders for JSON (json, application/json)
+// and YAML (yaml, yml, application/yaml, text/yaml) are available by default.
+//
+//	WithDocStringDecoder("xml", xml.Unmarshal)
+func WithDocStringDecoder(mediaType string, decoder func(data []byte, v interface{}) error) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.docStringDecoders[strings.ToLower(mediaType)] = decoder
+	}
+}
+
+// docStringValue is the doc string passed to the step function, it's converted depending on the argument's type
+type docStringValue struct {
+	docString DocString
+	decoders  map[string]func(data []byte, v interface{}) error
+}
+
+// convert converts the doc string to the type of the step function's argument. Strings and other types supported
+// by checkTextType get the content, DocString gets the content with the media type, other types are decoded
+// with the decoder configured for the media type. The decoder isn't called when decode is false (in dry run).
+func (v docStringValue) convert(inType reflect.Type, decode bool) (reflect.Value, error) {
+	switch {
+	case inType == docStringType:
+		return reflect.ValueOf(v.docString), nil
+	case checkTextType(inType) == nil:
+		return paramType(v.docString.Content, inType)
+	}
+
+	decoder, ok := v.decoders[strings.ToLower(v.docString.MediaType)]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("the doc string with the media type %q cannot be decoded to %s, "+
+			"there's no decoder for the media type", v.docString.MediaType, inType)
+	}
+
+	if !decode {
+		return reflect.Zero(inType), nil
+	}
+
+	ptr := reflect.New(inType)
+	if err := decoder([]byte(v.docString.Content), ptr.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot decode the doc string with the media type %q to %s: %w",
+			v.docString.MediaType, inType, err)
+	}
+
+	return ptr.Elem(), nil
+}
+
+// checkDocStringType checks whether doc strings can be decoded to the type
+func checkDocStringType(inType reflect.Type) error {
+	switch inType.Kind() { // nolint:exhaustive // the linter does not recognize 'default:' to satisfy exhaustiveness
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return nil
+	case reflect.Interface:
+		if inType.NumMethod() == 0 {
+			return nil
+		}
+	case reflect.Ptr:
+		return checkDocStringType(inType.Elem())
+	}
+
+	return fmt.Errorf("the type %s is not supported", inType)
+}
@@ -0,0 +1,111 @@
+package gobdd
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+type docStringUser struct {
+	Name string `json:"name" yaml:"name"`
+	Age  int    `json:"age" yaml:"age"`
+}
+
+func TestDocStrings(t *testing.T) {
+	var (
+		jsonUser  docStringUser
+		yamlUser  *docStringUser
+		raw       json.RawMessage
+		docString DocString
+		csvUsers  [][]string
+	)
+
+	suite := NewSuite(t, WithFeaturesPath("features/docstring.feature"),
+		WithDocStringDecoder("CSV", func(data []byte, v interface{}) error {
+			rows, ok := v.(*[][]string)
+			if !ok {
+				return errors.New("only [][]string is supported")
+			}
+
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				*rows = append(*rows, strings.Split(line, ","))
+			}
+
+			return nil
+		}))
+	suite.AddStep(`the JSON user:`, func(_ StepTest, _ Context, u docStringUser) {
+		jsonUser = u
+	})
+	suite.AddStep(`the YAML user:`, func(_ StepTest, _ Context, u *docStringUser) {
+		yamlUser = u
+	})
+	suite.AddStep(`the raw JSON:`, func(_ StepTest, _ Context, r json.RawMessage) {
+		raw = r
+	})
+	suite.AddStep(`the CSV doc string:`, func(_ StepTest, _ Context, d DocString) {
+		docString = d
+	})
+	suite.AddStep(`the CSV users:`, func(_ StepTest, _ Context, rows [][]string) {
+		csvUsers = rows
+	})
+
+	suite.Run()
+
+	require.Equal(t, docStringUser{Name: "alice", Age: 30}, jsonUser)
+	require.Equal(t, &docStringUser{Name: "bob", Age: 25}, yamlUser)
+	require.JSONEq(t, `{"id": 1}`, string(raw))
+	require.Equal(t, DocString{Content: "alice,30", MediaType: "csv"}, docString)
+	require.Equal(t, [][]string{{"alice", "30"}}, csvUsers)
+}
+
+func TestDocStringValue_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		docString DocString
+		inType    reflect.Type
+		expected  string
+	}{
+		"unknown media type": {
+			docString: DocString{Content: "<user/>", MediaType: "xml"},
+			inType:    reflect.TypeOf(docStringUser{}),
+			expected: `the doc string with the media type "xml" cannot be decoded to gobdd.docStringUser, ` +
+				`there's no decoder for the media type`,
+		},
+		"no media type": {
+			docString: DocString{Content: `{"name": "alice"}`},
+			inType:    reflect.TypeOf(docStringUser{}),
+			expected: `the doc string with the media type "" cannot be decoded to gobdd.docStringUser, ` +
+				`there's no decoder for the media type`,
+		},
+		"incorrect content": {
+			docString: DocString{Content: `{"name": 1}`, MediaType: "json"},
+			inType:    reflect.TypeOf(docStringUser{}),
+			expected: `cannot decode the doc string with the media type "json" to gobdd.docStringUser: ` +
+				`json: cannot unmarshal number into Go struct field docStringUser.name of type string`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			v := docStringValue{docString: testCase.docString, decoders: defaultDocStringDecoders()}
+			_, err := v.convert(testCase.inType, true)
+			require.EqualError(t, err, testCase.expected)
+		})
+	}
+}
+
+func TestDocStringValue_Content(t *testing.T) {
+	def := stepDef{expr: regexp.MustCompile(`^the doc string:$`), f: func(StepTest, Context, string) {}}
+	step := &msgs.Step{Text: "the doc string:", DocString: &msgs.DocString{Content: `{"a": 1}`, MediaType: "json"}}
+
+	arguments, err := def.arguments(nil, def.params(step, defaultDocStringDecoders()))
+	require.NoError(t, err)
+	require.Equal(t, `{"a": 1}`, arguments[0].Interface())
+}
@@ -0,0 +1,48 @@
+package gobdd
+
+import (
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// WithDryRun checks all the scenarios without executing them: every step is resolved against registered
+// step definitions, but neither step functions, hooks, transformers of custom parameter types nor doc string
+// decoders are called. Undefined steps and steps whose parameters don't fit the step function are reported
+// as errors, ambiguous steps are handled like in the real run (see WithFailOnAmbiguousSteps).
+// Steps which could be executed are reported as skipped.
+func WithDryRun() func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.dryRun = true
+	}
+}
+
+// dryRunStep checks whether the step could be executed without calling the step function. Transformers
+// of parameter types added with AddParameterType and doc string decoders aren't called either,
+// only types of the step function's arguments are checked.
+func (s *Suite) dryRunStep(run *testRun, t StepTest, tc *testCase, step *testStep) {
+	result := stepResult{status: msgs.TestStepResultStatus_SKIPPED}
+
+	switch {
+	case step.def == nil:
+		result.status = msgs.TestStepResultStatus_UNDEFINED
+		result.err = errUndefinedStep(step)
+	case len(step.ambiguous) > 0 && s.options.failOnAmbiguousSteps:
+		result.status = msgs.TestStepResultStatus_AMBIGUOUS
+		result.err = errAmbiguousStep(step, step.ambiguous)
+	default:
+		// ambiguous steps are checked with the step definition used by the real run
+		if len(step.ambiguous) > 0 {
+			s.warnAboutAmbiguousStep(run, step, errAmbiguousStep(step, step.ambiguous))
+		}
+
+		if _, err := step.def.arguments(nil, step.def.params(step.step, s.options.docStringDecoders)); err != nil {
+			result.status = msgs.TestStepResultStatus_FAILED
+			result.err = errStepArguments(tc, step, err)
+		}
+	}
+
+	if result.err != nil {
+		t.Error(result.err.Error())
+	}
+
+	s.notifyStep(tc, step, result)
+}
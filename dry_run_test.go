@@ -0,0 +1,172 @@
+package gobdd
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDryRun(t *testing.T) {
+	f := &recordingFormatter{}
+	hooks := 0
+	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"), WithDryRun(), WithFormatters(f),
+		WithBeforeScenario(func(Context) { hooks++ }),
+		WithBeforeStep(func(Context) { hooks++ }))
+	suite.AddStep(`I add (\d+) and (\d+)`, func(t StepTest, _ Context, _, _ int) {
+		t.Error("the step should never be executed")
+	})
+	suite.AddStep(`the result should equal (\d+)`, func(t StepTest, _ Context, _ int) {
+		t.Error("the step should never be executed")
+	})
+
+	suite.Run()
+
+	require.Equal(t, 0, hooks)
+	require.True(t, f.run.Success)
+	require.Contains(t, f.events, "step finished: I add 5 and 5 (SKIPPED)")
+	require.Contains(t, f.events, "scenario finished: testing outline scenarios (Examples #1.2) (SKIPPED)")
+}
+
+func TestDryRunStep(t *testing.T) {
+	testCases := map[string]struct {
+		step   *msgs.Step
+		status msgs.TestStepResultStatus
+		err    string
+	}{
+		"defined": {
+			step:   &msgs.Step{Keyword: "When ", Text: "I add 1 and 2"},
+			status: msgs.TestStepResultStatus_SKIPPED,
+		},
+		"undefined": {
+			step:   &msgs.Step{Keyword: "When ", Text: "I multiply 1 and 2"},
+			status: msgs.TestStepResultStatus_UNDEFINED,
+			err:    "cannot find step definition for step: When I multiply 1 and 2",
+		},
+		"ambiguous": {
+			step:   &msgs.Step{Keyword: "Then ", Text: "the result should equal 3"},
+			status: msgs.TestStepResultStatus_AMBIGUOUS,
+			err: "the step Then the result should equal 3 matches many step definitions:\n" +
+				"\tthe result should equal (\\d+) (",
+		},
+		"wrong number of arguments": {
+			step: &msgs.Step{
+				Keyword: "Given ", Text: "the text", DocString: &msgs.DocString{Content: "text"}, Location: &msgs.Location{Line: 4},
+			},
+			status: msgs.TestStepResultStatus_FAILED,
+			err:    "the step Given the text (features/dry_run.feature:4) cannot be executed: the step function",
+		},
+		"wrong type of argument": {
+			step:   &msgs.Step{Keyword: "Given ", Text: "the number abc", Location: &msgs.Location{Line: 5}},
+			status: msgs.TestStepResultStatus_FAILED,
+			err: "the step Given the number abc (features/dry_run.feature:5) cannot be executed: " +
+				"cannot convert \"abc\" to int",
+		},
+	}
+
+	suite := NewSuite(t, WithFailOnAmbiguousSteps())
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+	suite.AddStep(`the result should equal {int}`, checkf)
+	suite.AddStep(`the text`, pass)
+	suite.AddStep(`the number (\w+)`, func(StepTest, Context, int) {})
+
+	for name, example := range testCases {
+		example := example
+
+		t.Run(name, func(t *testing.T) {
+			tester := &mockTester{}
+			step := &testStep{step: example.step}
+			if def, ambiguous, err := suite.findStepDef(example.step.Text); err == nil {
+				step.def = &def
+				step.ambiguous = ambiguous
+			}
+
+			tc := &testCase{uri: "features/dry_run.feature", steps: []*testStep{step}}
+
+			suite.dryRunStep(&testRun{ambiguousSteps: map[string]bool{}}, tester, tc, step)
+
+			require.Equal(t, example.status, step.result.status)
+
+			if example.err == "" {
+				require.NoError(t, step.result.err)
+				require.Empty(t, tester.errors)
+
+				return
+			}
+
+			require.Error(t, step.result.err)
+			require.True(t, strings.HasPrefix(step.result.err.Error(), example.err), step.result.err.Error())
+			require.Equal(t, []string{step.result.err.Error()}, tester.errors)
+		})
+	}
+}
+
+func TestWithDryRun_AmbiguousSteps(t *testing.T) {
+	f := &recordingFormatter{}
+	tester := &logRecorder{T: t}
+	suite := NewSuite(tester, WithFeaturesPath("features/background.feature"), WithDryRun(), WithFormatters(f))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I add {int} and {int}`, addf)
+	suite.AddStep(`the result should equal (\d+)`, check)
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+
+	suite.Run()
+
+	warnings := 0
+
+	for _, message := range tester.logs {
+		if strings.Contains(message, "matches many step definitions") {
+			warnings++
+		}
+	}
+
+	require.Equal(t, 1, warnings)
+	require.True(t, f.run.Success)
+	require.Contains(t, f.events, "step finished: I add 1 and 2 (SKIPPED)")
+}
+
+func TestWithDryRun_UserConversions(t *testing.T) {
+	calls := 0
+	decode := func([]byte, interface{}) error {
+		calls++
+
+		return nil
+	}
+
+	suite := NewSuite(t, WithFeaturesPath("features/custom-parameter-types.feature"), WithDryRun(),
+		WithCucumberExpressions())
+	suite.AddParameterType(`{color}`, []string{`red|green|blue`}, func(value string) (color, error) {
+		calls++
+
+		return color(value), nil
+	})
+	suite.AddStep(`the user {word}`, func(StepTest, Context, string) {})
+	suite.AddStep(`{word} paints the fence {color} on {word}`, func(StepTest, Context, string, color, string) {})
+	suite.AddStep(`the fence is {color}`, func(StepTest, Context, color) {})
+	suite.Run()
+
+	def := stepDef{expr: regexp.MustCompile(`^the user:$`), f: func(StepTest, Context, *user) {}}
+	step := &msgs.Step{Text: "the user:", DocString: &msgs.DocString{Content: `{"name": "alice"}`, MediaType: "json"}}
+	_, err := def.arguments(nil, def.params(step, map[string]func([]byte, interface{}) error{"json": decode}))
+
+	require.NoError(t, err)
+	require.Equal(t, 0, calls)
+}
+
+func TestWithDryRun_PrettyOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/example_rule.feature"), WithDryRun(), WithPrettyOutput(buf))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	require.Contains(t, buf.String(), "1 scenario (1 skipped)\n2 steps (2 skipped)\n")
+}
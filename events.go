@@ -0,0 +1,291 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// listener observes the execution of the suite, for example to produce reports
+type listener interface {
+	runStarted(run *testRun)
+	featureStarted(doc *featureDocument)
+	ruleStarted(doc *featureDocument, rule *msgs.Rule)
+	testCaseStarted(tc *testCase)
+	stepStarted(tc *testCase, step *testStep)
+	stepFinished(tc *testCase, step *testStep, result stepResult)
+	// hookStarted and hookFinished are called for scenario and step hooks, which are reported as test steps
+	hookStarted(tc *testCase, hs *hookStep)
+	hookFinished(tc *testCase, hs *hookStep)
+	attached(tc *testCase, step *testStep, a *attachment)
+	testCaseFinished(tc *testCase)
+	// testCaseSkipped is called for test cases which are not executed because of ignored tags
+	testCaseSkipped(tc *testCase)
+	ruleFinished(doc *featureDocument, rule *msgs.Rule)
+	featureFinished(doc *featureDocument)
+	// runFinished is called at the very end of the suite. Reporters writing the whole report at once
+	// should do it here.
+	runFinished(run *testRun) error
+}
+
+// baseListener implements all methods of the listener doing nothing, so listeners can embed it
+// and implement only the events they are interested in
+type baseListener struct{}
+
+func (baseListener) runStarted(*testRun) {}
+
+func (baseListener) featureStarted(*featureDocument) {}
+
+func (baseListener) ruleStarted(*featureDocument, *msgs.Rule) {}
+
+func (baseListener) testCaseStarted(*testCase) {}
+
+func (baseListener) stepStarted(*testCase, *testStep) {}
+
+func (baseListener) stepFinished(*testCase, *testStep, stepResult) {}
+
+func (baseListener) hookStarted(*testCase, *hookStep) {}
+
+func (baseListener) hookFinished(*testCase, *hookStep) {}
+
+func (baseListener) attached(*testCase, *testStep, *attachment) {}
+
+func (baseListener) testCaseFinished(*testCase) {}
+
+func (baseListener) testCaseSkipped(*testCase) {}
+
+func (baseListener) ruleFinished(*featureDocument, *msgs.Rule) {}
+
+func (baseListener) featureFinished(*featureDocument) {}
+
+func (baseListener) runFinished(*testRun) error {
+	return nil
+}
+
+// testRun holds information about the whole suite execution
+type testRun struct {
+	id       string
+	started  time.Time
+	stepDefs []stepDef
+	// success is false when at least one of test cases didn't pass
+	success bool
+	// skipped is true when the before suite hook failed, so none of features is executed
+	skipped bool
+	// undefinedSteps are steps without matching step definitions from all test cases
+	undefinedSteps []*msgs.Step
+	// ambiguousSteps holds texts of ambiguous steps which have already been reported
+	ambiguousSteps map[string]bool
+	// hooks are scenario and step hooks, which are reported as test steps
+	hooks []hook
+}
+
+// featureDocument is a parsed feature file
+type featureDocument struct {
+	uri      string
+	source   []byte
+	document *msgs.GherkinDocument
+	pickles  []*msgs.Pickle
+}
+
+// pickle finds the pickle compiled from the scenario or from the row of the scenario outline
+func (doc *featureDocument) pickle(scenario *msgs.Scenario, row *msgs.TableRow) *msgs.Pickle {
+	for _, pickle := range doc.pickles {
+		ids := pickle.AstNodeIds
+		if len(ids) == 0 || ids[0] != scenario.Id {
+			continue
+		}
+
+		if row == nil || len(ids) > 1 && ids[1] == row.Id {
+			return pickle
+		}
+	}
+
+	return nil
+}
+
+// testCase is a single execution of a scenario or a single row of a scenario outline
+type testCase struct {
+	id       string
+	uri      string
+	feature  *msgs.Feature
+	rule     *msgs.Rule
+	scenario *msgs.Scenario
+	// examples and row are set for scenario outlines only
+	examples *msgs.Examples
+	row      *msgs.TableRow
+	// exampleName identifies the row of the scenario outline, like "Examples #1.2"
+	exampleName string
+	pickle      *msgs.Pickle
+	// tags are tags of the scenario and the Examples block, without tags inherited from the feature or the rule
+	tags []*msgs.Tag
+	// allTags are tags of the test case together with tags inherited from the feature and the rule
+	allTags []*msgs.Tag
+	steps   []*testStep
+	// beforeHooks and afterHooks are scenario hooks matching tags of the test case
+	beforeHooks []*hookStep
+	afterHooks  []*hookStep
+	// timeout is the timeout of the whole test case, zero means there's no limit
+	timeout time.Duration
+	// retries is the number of times the failed test case is retried, attempt counts from 0
+	retries       int
+	attempt       int
+	willBeRetried bool
+	// flaky is true when the test case passed after failed attempts
+	flaky bool
+
+	startedID string
+	started   time.Time
+	duration  time.Duration
+	status    msgs.TestStepResultStatus
+	// err is the error of the first step which didn't pass
+	err error
+}
+
+// name returns the name of the scenario with the outline's placeholders replaced and the row identifier, if any
+func (tc *testCase) name() string {
+	name := tc.scenario.Name
+	if tc.pickle != nil {
+		name = tc.pickle.Name
+	}
+
+	if tc.exampleName != "" {
+		name = fmt.Sprintf("%s (%s)", name, tc.exampleName)
+	}
+
+	return name
+}
+
+// finishStep updates the status of the test case with the result of the step
+func (tc *testCase) finishStep(step *testStep, result stepResult) {
+	step.result = result
+
+	if statusPriority[result.status] > statusPriority[tc.status] {
+		tc.status = result.status
+	}
+
+	if tc.err == nil && result.err != nil {
+		tc.err = result.err
+	}
+}
+
+// failHook fails the test case because of the error of the scenario hook, if any
+func (tc *testCase) failHook(err error) {
+	if err == nil || errors.Is(err, ErrSkip) {
+		return
+	}
+
+	tc.status = msgs.TestStepResultStatus_FAILED
+
+	if tc.err == nil {
+		tc.err = err
+	}
+}
+
+// hookResult returns the result of the test case passed to after scenario hooks
+func (tc *testCase) hookResult() HookResult {
+	result := HookResult{Status: tc.status, Err: tc.err, Duration: time.Since(tc.started)}
+
+	for _, step := range tc.steps {
+		if step.result.err != nil {
+			result.FailedStep = step.step
+
+			break
+		}
+	}
+
+	return result
+}
+
+// testStep is a step of the test case together with the matching step definition
+type testStep struct {
+	id         string
+	step       *msgs.Step
+	pickleStep *msgs.PickleStep
+	// def is nil when there's no step definition matching the step
+	def *stepDef
+	// ambiguous holds all step definitions matching the step when there's more than one of them
+	ambiguous []stepDef
+	// partial holds step definitions matching only a part of the text of the undefined step
+	partial []stepDef
+	// background is set for steps coming from backgrounds
+	background *msgs.Background
+	// beforeHooks and afterHooks are step hooks matching tags of the test case
+	beforeHooks []*hookStep
+	afterHooks  []*hookStep
+	result      stepResult
+	attachments []*attachment
+}
+
+// hookStep is a single call of the scenario or the step hook within the test case. Hooks are reported as test steps
+// of their own, so failures of hooks are reported separately from failures of steps.
+type hookStep struct {
+	id   string
+	hook *hook
+	// step is the step the step hook is called for, it's nil for scenario hooks
+	step     *testStep
+	result   stepResult
+	finished bool
+}
+
+// newHookSteps creates test steps of hooks matching tags
+func (s *Suite) newHookSteps(hooks []hook, tags []string, step *testStep) []*hookStep {
+	steps := []*hookStep{}
+
+	for i := range hooks {
+		if hooks[i].tags.evaluate(tags) {
+			steps = append(steps, &hookStep{id: s.newID(), hook: &hooks[i], step: step})
+		}
+	}
+
+	return steps
+}
+
+// finish records the result of the hook returning the error
+func (hs *hookStep) finish(err error, d time.Duration) {
+	hs.finished = true
+	hs.result = stepResult{status: msgs.TestStepResultStatus_PASSED, duration: d, err: err}
+
+	switch {
+	case errors.Is(err, ErrSkip):
+		hs.result.status = msgs.TestStepResultStatus_SKIPPED
+	case err != nil:
+		hs.result.status = msgs.TestStepResultStatus_FAILED
+	}
+}
+
+// retryHookSteps returns copies of hooks with results reset
+func retryHookSteps(hooks []*hookStep, step *testStep) []*hookStep {
+	steps := make([]*hookStep, 0, len(hooks))
+
+	for _, hs := range hooks {
+		steps = append(steps, &hookStep{id: hs.id, hook: hs.hook, step: step})
+	}
+
+	return steps
+}
+
+// attachment is data attached to the step, like a screenshot or a log
+type attachment struct {
+	data      []byte
+	mediaType string
+	timestamp time.Time
+}
+
+type stepResult struct {
+	status   msgs.TestStepResultStatus
+	duration time.Duration
+	err      error
+}
+
+// statusPriority is used to determine the status of the test case based on statuses of its steps
+var statusPriority = map[msgs.TestStepResultStatus]int{
+	msgs.TestStepResultStatus_UNKNOWN:   0,
+	msgs.TestStepResultStatus_PASSED:    1,
+	msgs.TestStepResultStatus_SKIPPED:   2,
+	msgs.TestStepResultStatus_PENDING:   3,
+	msgs.TestStepResultStatus_UNDEFINED: 4,
+	msgs.TestStepResultStatus_AMBIGUOUS: 5,
+	msgs.TestStepResultStatus_FAILED:    6,
+}
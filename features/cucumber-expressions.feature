@@ -0,0 +1,11 @@
+Feature: cucumber expressions
+  Scenario: optional and alternative text
+    Given I have 1 cucumber in my belly
+    And I have 42 cucumbers in my stomach
+    Then I should have 43 cucumbers
+  Scenario: built-in parameter types
+    When I store 2.5 as a double and 9223372036854775807 as a long
+    And I store -128 as a byte and 32767 as a short
+    And I store 123456789012345678901234567890 as a big integer and 0.1 as a big decimal
+    And I store the string "say \"hello\""
+    And I store anything (in parentheses)
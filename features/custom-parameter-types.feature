@@ -0,0 +1,5 @@
+Feature: custom parameter types
+  Scenario: convert values with transformers
+    Given the user alice
+    When alice paints the fence red on 2024-03-15
+    Then the fence is red
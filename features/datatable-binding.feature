@@ -0,0 +1,15 @@
+Feature: data table binding
+  Scenario: bind tables to Go types
+    Given the users:
+      | name  | age | Email address     |
+      | alice | 30  | alice@example.com |
+      | bob   | 25  | bob@example.com   |
+    And the settings:
+      | theme    | dark |
+      | language | en   |
+    And the admin:
+      | name | carol |
+      | age  | 41    |
+    Then the matrix is:
+      | 1 | 2 |
+      | 3 | 4 |
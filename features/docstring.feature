@@ -0,0 +1,23 @@
+Feature: doc strings
+  Scenario: decode doc strings depending on the media type
+    Given the JSON user:
+      """json
+      {"name": "alice", "age": 30}
+      """
+    And the YAML user:
+      """yaml
+      name: bob
+      age: 25
+      """
+    And the raw JSON:
+      """json
+      {"id": 1}
+      """
+    And the CSV doc string:
+      """csv
+      alice,30
+      """
+    And the CSV users:
+      """csv
+      alice,30
+      """
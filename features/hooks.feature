@@ -0,0 +1,7 @@
+Feature: hooks
+  @db
+  Scenario: the scenario with the hook
+    Given the database is connected
+
+  Scenario: the scenario without the hook
+    Given the database is not connected
@@ -0,0 +1,9 @@
+Feature: retries
+  @retry(2)
+  Scenario: the scenario passes at the third attempt
+    Given the context is fresh
+    When the service is called
+    Then the service responds
+
+  Scenario: the scenario passes at once
+    Given the context is fresh
@@ -0,0 +1,9 @@
+Feature: skipping steps after the failure
+  Scenario: the failing step
+    Given the step fails at the first attempt
+    When the step is executed
+    Then the step is executed
+
+  Scenario: the skipped step
+    Given the step is skipped
+    When the step is executed
@@ -0,0 +1,8 @@
+Feature: context.Context
+  Background:
+    Given the request ID "abc" is added to the context
+
+  Scenario: pass values returned by steps to following steps
+    When the request is sent
+    Then the request ID is "abc"
+    And the scenario "pass values returned by steps to following steps" is available in the context
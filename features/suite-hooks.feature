@@ -0,0 +1,11 @@
+@server
+Feature: suite hooks
+  Scenario: the scenario of the feature
+    Then the server is "started"
+    And the rule is ""
+
+  @users
+  Rule: the rule
+    Scenario: the scenario of the rule
+      Then the server is "started"
+      And the rule is "the rule"
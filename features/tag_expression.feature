@@ -0,0 +1,31 @@
+@api
+Feature: tag expressions
+  @smoke
+  Scenario: the smoke scenario should pass
+    Then the test should pass
+  @smoke @slow
+  Scenario: the slow scenario should be ignored
+    Then fail the test
+  @wip
+  Scenario: the scenario in progress should be ignored
+    Then fail the test
+
+  @smoke
+  Scenario Outline: only not ignored examples should be executed
+    When I add <digit1> and <digit2>
+    Then the result should equal <result>
+    Examples:
+      | digit1 | digit2 | result |
+      | 1      | 2      | 3      |
+    @slow
+    Examples:
+      | digit1 | digit2 | result |
+      | 1      | 2      | 5      |
+
+  @smoke
+  Rule: tags are inherited from rules
+    Scenario: the scenario in the rule should pass
+      Then the test should pass
+    @wip
+    Scenario: the scenario in progress in the rule should be ignored
+      Then fail the test
@@ -0,0 +1,10 @@
+@timeout:1m
+Feature: timeouts
+  Scenario: steps have deadlines
+    Given the step has a deadline
+    When the step takes 10ms
+    Then the step has a deadline
+
+  @timeout:2m
+  Scenario: the timeout tag of the scenario overrides the feature's one
+    Given the step has a deadline
@@ -0,0 +1,320 @@
+package gobdd
+
+import (
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// Formatter receives events about the execution of the suite. It can be used to write custom reports
+// or to collect metrics. Embed BaseFormatter to implement only the events you are interested in.
+//
+// Events are delivered in the order of execution:
+//
+//	RunStarted
+//	  FeatureStarted
+//	    RuleStarted (for scenarios inside a rule only)
+//	      ScenarioStarted
+//	        HookFinished (for every before scenario hook)
+//	        HookFinished (for every before step hook)
+//	        StepStarted, StepFinished (for every step, backgrounds included)
+//	        HookFinished (for every after step hook)
+//	        HookFinished (for every after scenario hook)
+//	      ScenarioFinished
+//	    RuleFinished
+//	  FeatureFinished
+//	RunFinished
+//
+// Every row of a scenario outline is reported as a separate scenario. Scenarios which are ignored
+// because of their tags are reported with the SKIPPED status and without steps. Every attempt
+// of a retried scenario is reported separately, see ScenarioEvent.WillBeRetried. Failures of hooks
+// are reported with HookFinished, they fail the scenario, but not the step the hook is called for.
+type Formatter interface {
+	RunStarted(event RunEvent)
+	FeatureStarted(event FeatureEvent)
+	RuleStarted(event RuleEvent)
+	ScenarioStarted(event ScenarioEvent)
+	StepStarted(event StepEvent)
+	StepFinished(event StepEvent)
+	HookFinished(event HookEvent)
+	ScenarioFinished(event ScenarioEvent)
+	RuleFinished(event RuleEvent)
+	FeatureFinished(event FeatureEvent)
+	// RunFinished is called at the very end of the suite. The returned error fails the suite.
+	RunFinished(event RunEvent) error
+}
+
+// RunEvent describes the execution of the whole suite
+type RunEvent struct {
+	Started time.Time
+	// Duration and Success are set when the run is finished.
+	// Success is false when at least one of scenarios didn't pass.
+	Duration time.Duration
+	Success  bool
+}
+
+// FeatureEvent describes the executed feature
+type FeatureEvent struct {
+	// URI identifies the feature, usually it's the path to the feature file
+	URI      string
+	Document *msgs.GherkinDocument
+	Feature  *msgs.Feature
+}
+
+// RuleEvent describes the executed rule
+type RuleEvent struct {
+	URI     string
+	Feature *msgs.Feature
+	Rule    *msgs.Rule
+}
+
+// ScenarioEvent describes a single execution of a scenario or of a single row of a scenario outline
+type ScenarioEvent struct {
+	URI     string
+	Feature *msgs.Feature
+	// Rule is nil when the scenario doesn't belong to any rule
+	Rule     *msgs.Rule
+	Scenario *msgs.Scenario
+	// Examples and Row are set for scenario outlines only
+	Examples *msgs.Examples
+	Row      *msgs.TableRow
+	// Name is the name of the scenario with outline's placeholders replaced and the row identifier, if any
+	Name string
+	// Tags holds names of all tags of the scenario, including tags inherited from the feature, the rule
+	// and the Examples block
+	Tags    []string
+	Started time.Time
+	// Status, Err and Duration are set when the scenario is finished.
+	// Err is the error of the first step which didn't pass.
+	Status   msgs.TestStepResultStatus
+	Err      error
+	Duration time.Duration
+	// Attempt counts attempts of the retried scenario from 0. WillBeRetried is set when the scenario failed
+	// and it's going to be executed again, Flaky when the scenario passed after failed attempts.
+	Attempt       int
+	WillBeRetried bool
+	Flaky         bool
+}
+
+// StepEvent describes the executed step
+type StepEvent struct {
+	Scenario *ScenarioEvent
+	Step     *msgs.Step
+	// Background is set for steps coming from backgrounds
+	Background *msgs.Background
+	// Definition is nil when there's no step definition matching the step
+	Definition *StepDefinition
+	// Arguments are values captured by the step definition in the step's text
+	Arguments []string
+	// Status, Err and Duration are set when the step is finished
+	Status   msgs.TestStepResultStatus
+	Err      error
+	Duration time.Duration
+}
+
+// HookEvent describes the finished call of the scenario or the step hook
+type HookEvent struct {
+	Scenario *ScenarioEvent
+	// Step is the step the step hook is called for, it's nil for scenario hooks
+	Step *msgs.Step
+	// Kind tells when the hook is called: "before scenario", "after scenario", "before step" or "after step"
+	Kind string
+	// File and Line point to the place where the hook has been registered
+	File     string
+	Line     int
+	Status   msgs.TestStepResultStatus
+	Err      error
+	Duration time.Duration
+}
+
+// StepDefinition describes the step registered in the suite
+type StepDefinition struct {
+	// Expression is the expression passed while registering the step
+	Expression string
+	// File and Line point to the place where the step has been registered
+	File string
+	Line int
+}
+
+// BaseFormatter implements all methods of the Formatter doing nothing
+type BaseFormatter struct{}
+
+func (BaseFormatter) RunStarted(RunEvent) {}
+
+func (BaseFormatter) FeatureStarted(FeatureEvent) {}
+
+func (BaseFormatter) RuleStarted(RuleEvent) {}
+
+func (BaseFormatter) ScenarioStarted(ScenarioEvent) {}
+
+func (BaseFormatter) StepStarted(StepEvent) {}
+
+func (BaseFormatter) StepFinished(StepEvent) {}
+
+func (BaseFormatter) HookFinished(HookEvent) {}
+
+func (BaseFormatter) ScenarioFinished(ScenarioEvent) {}
+
+func (BaseFormatter) RuleFinished(RuleEvent) {}
+
+func (BaseFormatter) FeatureFinished(FeatureEvent) {}
+
+func (BaseFormatter) RunFinished(RunEvent) error {
+	return nil
+}
+
+// WithFormatters registers formatters which receive events about the execution of the suite.
+// The option can be used many times, every registered formatter receives all the events.
+func WithFormatters(formatters ...Formatter) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		for _, f := range formatters {
+			options.listeners = append(options.listeners, &formatterListener{formatter: f})
+		}
+	}
+}
+
+// formatterListener translates internal events into events of the public Formatter
+type formatterListener struct {
+	baseListener
+
+	formatter Formatter
+}
+
+func (l *formatterListener) runStarted(run *testRun) {
+	l.formatter.RunStarted(RunEvent{Started: run.started})
+}
+
+func (l *formatterListener) featureStarted(doc *featureDocument) {
+	l.formatter.FeatureStarted(featureEvent(doc))
+}
+
+func (l *formatterListener) ruleStarted(doc *featureDocument, rule *msgs.Rule) {
+	l.formatter.RuleStarted(RuleEvent{URI: doc.uri, Feature: doc.document.Feature, Rule: rule})
+}
+
+func (l *formatterListener) testCaseStarted(tc *testCase) {
+	l.formatter.ScenarioStarted(scenarioEvent(tc, false))
+}
+
+func (l *formatterListener) stepStarted(tc *testCase, step *testStep) {
+	l.formatter.StepStarted(stepEvent(tc, step))
+}
+
+func (l *formatterListener) stepFinished(tc *testCase, step *testStep, result stepResult) {
+	event := stepEvent(tc, step)
+	event.Status = result.status
+	event.Err = result.err
+	event.Duration = result.duration
+
+	l.formatter.StepFinished(event)
+}
+
+func (l *formatterListener) hookFinished(tc *testCase, hs *hookStep) {
+	scenario := scenarioEvent(tc, false)
+
+	event := HookEvent{
+		Scenario: &scenario,
+		Kind:     string(hs.hook.kind),
+		File:     hs.hook.file,
+		Line:     hs.hook.line,
+		Status:   hs.result.status,
+		Err:      hs.result.err,
+		Duration: hs.result.duration,
+	}
+
+	if hs.step != nil {
+		event.Step = hs.step.step
+	}
+
+	l.formatter.HookFinished(event)
+}
+
+func (l *formatterListener) testCaseFinished(tc *testCase) {
+	l.formatter.ScenarioFinished(scenarioEvent(tc, true))
+}
+
+func (l *formatterListener) testCaseSkipped(tc *testCase) {
+	l.formatter.ScenarioStarted(scenarioEvent(tc, false))
+	l.formatter.ScenarioFinished(scenarioEvent(tc, true))
+}
+
+func (l *formatterListener) ruleFinished(doc *featureDocument, rule *msgs.Rule) {
+	l.formatter.RuleFinished(RuleEvent{URI: doc.uri, Feature: doc.document.Feature, Rule: rule})
+}
+
+func (l *formatterListener) featureFinished(doc *featureDocument) {
+	l.formatter.FeatureFinished(featureEvent(doc))
+}
+
+func (l *formatterListener) runFinished(run *testRun) error {
+	return l.formatter.RunFinished(RunEvent{
+		Started:  run.started,
+		Duration: time.Since(run.started),
+		Success:  run.success,
+	})
+}
+
+func featureEvent(doc *featureDocument) FeatureEvent {
+	return FeatureEvent{
+		URI:      doc.uri,
+		Document: doc.document,
+		Feature:  doc.document.Feature,
+	}
+}
+
+// scenarioEvent describes the test case, the result is filled in only when the test case is finished
+func scenarioEvent(tc *testCase, finished bool) ScenarioEvent {
+	tags := tagNames(tc.feature.Tags)
+	if tc.rule != nil {
+		tags = append(tags, tagNames(tc.rule.Tags)...)
+	}
+
+	tags = append(tags, tagNames(tc.tags)...)
+
+	event := ScenarioEvent{
+		URI:      tc.uri,
+		Feature:  tc.feature,
+		Rule:     tc.rule,
+		Scenario: tc.scenario,
+		Examples: tc.examples,
+		Row:      tc.row,
+		Name:     tc.name(),
+		Tags:     tags,
+		Started:  tc.started,
+		Attempt:  tc.attempt,
+	}
+
+	if finished {
+		event.Status = tc.status
+		event.Err = tc.err
+		event.Duration = tc.duration
+		event.WillBeRetried = tc.willBeRetried
+		event.Flaky = tc.flaky
+	}
+
+	return event
+}
+
+func stepEvent(tc *testCase, step *testStep) StepEvent {
+	scenario := scenarioEvent(tc, false)
+
+	event := StepEvent{
+		Scenario:   &scenario,
+		Step:       step.step,
+		Background: step.background,
+	}
+
+	if step.def != nil {
+		event.Definition = &StepDefinition{
+			Expression: step.def.source,
+			File:       step.def.file,
+			Line:       step.def.line,
+		}
+
+		for _, m := range step.def.matchedGroups(step.step.Text) {
+			event.Arguments = append(event.Arguments, m.value)
+		}
+	}
+
+	return event
+}
@@ -0,0 +1,164 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFormatter records all the events as human-readable lines
+type recordingFormatter struct {
+	events []string
+	steps  []StepEvent
+	hooks  []HookEvent
+	run    RunEvent
+	err    error
+}
+
+func (f *recordingFormatter) RunStarted(RunEvent) {
+	f.events = append(f.events, "run started")
+}
+
+func (f *recordingFormatter) FeatureStarted(e FeatureEvent) {
+	f.events = append(f.events, "feature started: "+e.Feature.Name)
+}
+
+func (f *recordingFormatter) RuleStarted(e RuleEvent) {
+	f.events = append(f.events, "rule started: "+e.Rule.Name)
+}
+
+func (f *recordingFormatter) ScenarioStarted(e ScenarioEvent) {
+	f.events = append(f.events, "scenario started: "+e.Name)
+}
+
+func (f *recordingFormatter) StepStarted(e StepEvent) {
+	f.events = append(f.events, "step started: "+e.Step.Text)
+}
+
+func (f *recordingFormatter) StepFinished(e StepEvent) {
+	f.events = append(f.events, fmt.Sprintf("step finished: %s (%s)", e.Step.Text, e.Status))
+	f.steps = append(f.steps, e)
+}
+
+func (f *recordingFormatter) HookFinished(e HookEvent) {
+	f.events = append(f.events, fmt.Sprintf("hook finished: %s (%s)", e.Kind, e.Status))
+	f.hooks = append(f.hooks, e)
+}
+
+func (f *recordingFormatter) ScenarioFinished(e ScenarioEvent) {
+	f.events = append(f.events, fmt.Sprintf("scenario finished: %s (%s)", e.Name, e.Status))
+}
+
+func (f *recordingFormatter) RuleFinished(e RuleEvent) {
+	f.events = append(f.events, "rule finished: "+e.Rule.Name)
+}
+
+func (f *recordingFormatter) FeatureFinished(e FeatureEvent) {
+	f.events = append(f.events, "feature finished: "+e.Feature.Name)
+}
+
+func (f *recordingFormatter) RunFinished(e RunEvent) error {
+	f.events = append(f.events, "run finished")
+	f.run = e
+
+	return f.err
+}
+
+func TestWithFormatters(t *testing.T) {
+	first, second := &recordingFormatter{}, &recordingFormatter{}
+	suite := NewSuite(t, WithFeaturesPath("features/example_rule.feature"), WithFormatters(first, second))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	expected := []string{
+		"run started",
+		"feature started: math operations",
+		"rule started: add things",
+		"scenario started: add two digits",
+		"step started: I add 1 and 2",
+		"step finished: I add 1 and 2 (PASSED)",
+		"step started: the result should equal 3",
+		"step finished: the result should equal 3 (PASSED)",
+		"scenario finished: add two digits (PASSED)",
+		"rule finished: add things",
+		"feature finished: math operations",
+		"run finished",
+	}
+
+	require.Equal(t, expected, first.events)
+	require.Equal(t, expected, second.events)
+	require.True(t, first.run.Success)
+
+	step := first.steps[0]
+	require.Equal(t, []string{"1", "2"}, step.Arguments)
+	require.NotNil(t, step.Definition)
+	require.Equal(t, `I add (\d+) and (\d+)`, step.Definition.Expression)
+	require.Contains(t, step.Definition.File, "formatter_test.go")
+	require.Equal(t, "add things", step.Scenario.Rule.Name)
+}
+
+func TestWithFormatters_Outline(t *testing.T) {
+	f := &recordingFormatter{}
+	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"), WithFormatters(f))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	require.Contains(t, f.events, "scenario finished: testing outline scenarios (Examples #1.1) (PASSED)")
+	require.Contains(t, f.events, "scenario finished: testing outline scenarios (Examples #1.2) (PASSED)")
+	require.Contains(t, f.events, "step finished: I add 5 and 5 (PASSED)")
+}
+
+func TestWithFormatters_IgnoredRule(t *testing.T) {
+	f := &recordingFormatter{}
+	suite := NewSuite(t, WithFeaturesPath("features/ignored_rule_tags.feature"), WithIgnoredTags("@ignore"),
+		WithFormatters(f))
+	suite.AddStep(`the test should pass`, pass)
+	suite.AddStep(`fail the test`, fail)
+
+	suite.Run()
+
+	require.Equal(t, []string{
+		"run started",
+		"feature started: ignored tags",
+		"rule started: this rule should be ignored",
+		"scenario started: the scenario should be ignored",
+		"scenario finished: the scenario should be ignored (SKIPPED)",
+		"rule finished: this rule should be ignored",
+		"rule started: this rule should run",
+		"scenario started: the scenario should pass",
+		"step started: the test should pass",
+		"step finished: the test should pass (PASSED)",
+		"scenario finished: the scenario should pass (PASSED)",
+		"rule finished: this rule should run",
+		"feature finished: ignored tags",
+		"run finished",
+	}, f.events)
+}
+
+func TestWithFormatters_RunFinishedError(t *testing.T) {
+	f := &recordingFormatter{err: errors.New("cannot send the summary")}
+	tester := &mockTester{}
+	suite := NewSuite(tester, WithFeaturesPath("features/missing_*.feature"), WithFormatters(f))
+
+	suite.Run()
+
+	require.Equal(t, []string{"the formatter failed: cannot send the summary"}, tester.errors)
+}
+
+func TestBaseFormatter(t *testing.T) {
+	f := &struct {
+		BaseFormatter
+	}{}
+
+	suite := NewSuite(t, WithFeaturesPath("features/example_rule.feature"), WithFormatters(f))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+}
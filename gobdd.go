@@ -1,7 +1,9 @@
 package gobdd
 
 import (
-	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,9 +11,11 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	gherkin "github.com/cucumber/gherkin/go/v33"
 	msgs "github.com/cucumber/messages/go/v28"
@@ -21,11 +25,14 @@ const contextArgumentsNumber = 2
 
 // Suite holds all the information about the suite (options, steps to execute etc)
 type Suite struct {
-	t              TestingT
-	steps          []stepDef
-	options        SuiteOptions
-	hasStepErrors  bool
-	parameterTypes map[string][]string
+	t             TestingT
+	steps         []stepDef
+	options       SuiteOptions
+	hasStepErrors bool
+	// parameterTypes are keyed by the name in braces, like {int}
+	parameterTypes map[string]*parameterType
+	tagExpression  tagExpression
+	newID          func() string
 }
 
 // SuiteOptions holds all the information about how the suite or features/steps should be configured
@@ -33,11 +40,29 @@ type SuiteOptions struct {
 	featureSource  featureSource
 	ignoreTags     []string
 	tags           []string
-	beforeScenario []func(ctx Context)
-	afterScenario  []func(ctx Context)
-	beforeStep     []func(ctx Context)
-	afterStep      []func(ctx Context)
+	tagExpression  string
+	beforeSuite    []hook
+	afterSuite     []hook
+	beforeFeature  []hook
+	afterFeature   []hook
+	beforeRule     []hook
+	afterRule      []hook
+	beforeScenario []hook
+	afterScenario  []hook
+	beforeStep     []hook
+	afterStep      []hook
 	runInParallel  bool
+	dryRun         bool
+	// failOnAmbiguousSteps makes steps matching many step definitions fail instead of logging a warning
+	failOnAmbiguousSteps bool
+	fullTextStepMatching bool
+	cucumberExpressions  bool
+	listeners            []listener
+	// docStringDecoders are keyed by lowercase media types
+	docStringDecoders map[string]func(data []byte, v interface{}) error
+	stepTimeout       time.Duration
+	scenarioTimeout   time.Duration
+	retries           int
 }
 
 type featureSource interface {
@@ -46,6 +71,8 @@ type featureSource interface {
 
 type feature interface {
 	Open() (io.Reader, error)
+	// URI identifies the feature in reports, usually it's the path to the file
+	URI() string
 }
 
 type pathFeatureSource string
@@ -76,16 +103,27 @@ func (f fileFeature) Open() (io.Reader, error) {
 	return file, nil
 }
 
+func (f fileFeature) URI() string {
+	return filepath.ToSlash(string(f))
+}
+
 // NewSuiteOptions creates a new suite configuration with default values
 func NewSuiteOptions() SuiteOptions {
 	return SuiteOptions{
-		featureSource:  pathFeatureSource("features/*.feature"),
-		ignoreTags:     []string{},
-		tags:           []string{},
-		beforeScenario: []func(ctx Context){},
-		afterScenario:  []func(ctx Context){},
-		beforeStep:     []func(ctx Context){},
-		afterStep:      []func(ctx Context){},
+		featureSource:     pathFeatureSource("features/*.feature"),
+		ignoreTags:        []string{},
+		tags:              []string{},
+		beforeSuite:       []hook{},
+		afterSuite:        []hook{},
+		beforeFeature:     []hook{},
+		afterFeature:      []hook{},
+		beforeRule:        []hook{},
+		afterRule:         []hook{},
+		beforeScenario:    []hook{},
+		afterScenario:     []hook{},
+		beforeStep:        []hook{},
+		afterStep:         []hook{},
+		docStringDecoders: defaultDocStringDecoders(),
 	}
 }
 
@@ -112,31 +150,200 @@ func WithTags(tags ...string) func(*SuiteOptions) {
 	}
 }
 
-// WithBeforeScenario configures functions that should be executed before every scenario
-func WithBeforeScenario(f func(ctx Context)) func(*SuiteOptions) {
+// WithTagExpression configures a Cucumber tag expression which selects the scenarios to run, for example
+// `@smoke and not @slow` or `(@api or @ui) and not @wip`.
+// The expression is evaluated against tags inherited from the feature and the rule and,
+// for scenario outlines, against tags of every Examples block separately.
+// It can be combined with WithTags and WithIgnoredTags.
+func WithTagExpression(expr string) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.tagExpression = expr
+	}
+}
+
+// WithBeforeScenario configures functions that should be executed before every scenario.
+// The hook can be limited to scenarios matching a tag expression and ordered with options, like WithHookTags.
+func WithBeforeScenario(f func(ctx Context), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(f), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.beforeScenario = append(options.beforeScenario, h)
+	}
+}
+
+// WithAfterScenario configures functions that should be executed after every scenario.
+// The hook can be limited to scenarios matching a tag expression and ordered with options, like WithHookTags.
+func WithAfterScenario(f func(ctx Context), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(f), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.afterScenario = append(options.afterScenario, h)
+	}
+}
+
+// WithBeforeStep configures functions that should be executed before every step.
+// The hook can be limited to steps of scenarios matching a tag expression and ordered with options,
+// like WithHookTags.
+func WithBeforeStep(f func(ctx Context), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(f), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.beforeStep = append(options.beforeStep, h)
+	}
+}
+
+// WithAfterStep configures functions that should be executed after every step.
+// The hook can be limited to steps of scenarios matching a tag expression and ordered with options,
+// like WithHookTags.
+func WithAfterStep(f func(ctx Context), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(f), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.afterStep = append(options.afterStep, h)
+	}
+}
+
+// WithBeforeScenarioT configures functions that should be executed before every scenario. The hook receives
+// the StepTest of the scenario: calling Skip skips the scenario, calling Error or Fatal fails it.
+// The hook can be limited to scenarios matching a tag expression and ordered with options, like WithHookTags.
+func WithBeforeScenarioT(f func(t StepTest, ctx Context), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(func(t StepTest, ctx Context, _ HookResult) { f(t, ctx) }, optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.beforeScenario = append(options.beforeScenario, h)
+	}
+}
+
+// WithAfterScenarioT configures functions that should be executed after every scenario. The hook receives
+// the StepTest of the scenario and the result of the scenario, so it can, for example, collect logs only
+// when the scenario failed. Calling Error or Fatal fails the scenario.
+// The hook can be limited to scenarios matching a tag expression and ordered with options, like WithHookTags.
+func WithAfterScenarioT(f func(t StepTest, ctx Context, result HookResult),
+	optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(f, optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.afterScenario = append(options.afterScenario, h)
+	}
+}
+
+// WithBeforeStepT configures functions that should be executed before every step. The hook receives
+// the StepTest of the step: calling Skip skips the step, calling Error or Fatal fails it.
+// The hook can be limited to steps of scenarios matching a tag expression and ordered with options,
+// like WithHookTags.
+func WithBeforeStepT(f func(t StepTest, ctx Context), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(func(t StepTest, ctx Context, _ HookResult) { f(t, ctx) }, optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.beforeStep = append(options.beforeStep, h)
+	}
+}
+
+// WithAfterStepT configures functions that should be executed after every step. The hook receives
+// the StepTest of the step and the result of the step, so it can, for example, take a screenshot only
+// when the step failed. Calling Error or Fatal fails the step.
+// The hook can be limited to steps of scenarios matching a tag expression and ordered with options,
+// like WithHookTags.
+func WithAfterStepT(f func(t StepTest, ctx Context, result HookResult),
+	optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(f, optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.afterStep = append(options.afterStep, h)
+	}
+}
+
+// WithBeforeSuite configures functions that should be executed once before all the features.
+// Values set in the context are visible in contexts of all the scenarios.
+func WithBeforeSuite(f func(ctx Context)) func(*SuiteOptions) {
+	h := newHook(contextHook(f), nil)
+
+	return func(options *SuiteOptions) {
+		options.beforeSuite = append(options.beforeSuite, h)
+	}
+}
+
+// WithAfterSuite configures functions that should be executed once after all the features
+func WithAfterSuite(f func(ctx Context)) func(*SuiteOptions) {
+	h := newHook(contextHook(f), nil)
+
+	return func(options *SuiteOptions) {
+		options.afterSuite = append(options.afterSuite, h)
+	}
+}
+
+// WithBeforeFeature configures functions that should be executed before every feature.
+// Values set in the context are visible in contexts of all the scenarios of the feature.
+// The hook can be limited to features matching a tag expression and ordered with options, like WithHookTags.
+func WithBeforeFeature(f func(ctx Context, feature *msgs.Feature),
+	optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(func(ctx Context) { f(ctx, contextFeature(ctx)) }), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.beforeFeature = append(options.beforeFeature, h)
+	}
+}
+
+// WithAfterFeature configures functions that should be executed after every feature.
+// The hook can be limited to features matching a tag expression and ordered with options, like WithHookTags.
+func WithAfterFeature(f func(ctx Context, feature *msgs.Feature),
+	optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(func(ctx Context) { f(ctx, contextFeature(ctx)) }), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.afterFeature = append(options.afterFeature, h)
+	}
+}
+
+// WithBeforeRule configures functions that should be executed before every rule.
+// Values set in the context are visible in contexts of all the scenarios of the rule.
+// The hook can be limited to rules matching a tag expression and ordered with options, like WithHookTags.
+func WithBeforeRule(f func(ctx Context, rule *msgs.Rule), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(func(ctx Context) { f(ctx, contextRule(ctx)) }), optionClosures)
+
+	return func(options *SuiteOptions) {
+		options.beforeRule = append(options.beforeRule, h)
+	}
+}
+
+// WithAfterRule configures functions that should be executed after every rule.
+// The hook can be limited to rules matching a tag expression and ordered with options, like WithHookTags.
+func WithAfterRule(f func(ctx Context, rule *msgs.Rule), optionClosures ...func(*HookOptions)) func(*SuiteOptions) {
+	h := newHook(contextHook(func(ctx Context) { f(ctx, contextRule(ctx)) }), optionClosures)
+
 	return func(options *SuiteOptions) {
-		options.beforeScenario = append(options.beforeScenario, f)
+		options.afterRule = append(options.afterRule, h)
 	}
 }
 
-// WithAfterScenario configures functions that should be executed after every scenario
-func WithAfterScenario(f func(ctx Context)) func(*SuiteOptions) {
+// WithFailOnAmbiguousSteps makes steps matching many step definitions with the same priority fail.
+// By default, a warning listing all the matching step definitions is logged
+// and the one with the most matches is used.
+func WithFailOnAmbiguousSteps() func(*SuiteOptions) {
 	return func(options *SuiteOptions) {
-		options.afterScenario = append(options.afterScenario, f)
+		options.failOnAmbiguousSteps = true
 	}
 }
 
-// WithBeforeStep configures functions that should be executed before every step
-func WithBeforeStep(f func(ctx Context)) func(*SuiteOptions) {
+// WithFullTextStepMatching makes step expressions match the whole text of a step, as Cucumber Expressions do.
+// By default, it's enough when an expression matches a part of the text,
+// so `I add 1 and 2` matches the step `I add 1 and 2 and 3` as well.
+// Undefined steps list step definitions which match only a part of their text.
+func WithFullTextStepMatching() func(*SuiteOptions) {
 	return func(options *SuiteOptions) {
-		options.beforeStep = append(options.beforeStep, f)
+		options.fullTextStepMatching = true
 	}
 }
 
-// WithAfterStep configures functions that should be executed after every step
-func WithAfterStep(f func(ctx Context)) func(*SuiteOptions) {
+// WithCucumberExpressions makes AddStep treat expressions as Cucumber Expressions, with optional text like
+// `cucumber(s)`, alternative text like `belly/stomach` and parameter types like {int} or {string}.
+// Every expression is compiled to a single regular expression matching the whole text of a step
+// and captured values are converted by their parameter types.
+// By default, parameter types are replaced with regular expressions and the rest of the expression
+// is a regular expression itself.
+func WithCucumberExpressions() func(*SuiteOptions) {
 	return func(options *SuiteOptions) {
-		options.afterStep = append(options.afterStep, f)
+		options.cucumberExpressions = true
 	}
 }
 
@@ -148,9 +355,50 @@ func WithIgnoredTags(tags ...string) func(*SuiteOptions) {
 	}
 }
 
+// StepOptions holds all the information about how the step definition should be configured
+type StepOptions struct {
+	priority int
+	timeout  time.Duration
+}
+
+func newStepOptions(optionClosures []func(*StepOptions)) StepOptions {
+	options := StepOptions{}
+
+	for _, f := range optionClosures {
+		f(&options)
+	}
+
+	return options
+}
+
+// WithStepPriority configures the priority of the step definition. When many step definitions match a step,
+// the one with the highest priority is used, so it can be used to override steps coming from shared libraries
+// on purpose. The default priority is 0.
+func WithStepPriority(priority int) func(*StepOptions) {
+	return func(options *StepOptions) {
+		options.priority = priority
+	}
+}
+
 type stepDef struct {
+	id   string
 	expr *regexp.Regexp
-	f    interface{}
+	// unanchored is the expression before anchoring, it's set only when the full-text matching is enabled
+	unanchored *regexp.Regexp
+	f          interface{}
+	// source is the expression passed while registering the step
+	source string
+	// cucumberExpression is true when the source has been compiled as a Cucumber Expression
+	cucumberExpression bool
+	// parameters of the compiled Cucumber Expression, they're set only when Cucumber Expressions are enabled
+	parameters []expressionParameter
+	// file and line point to the place where the step has been registered
+	file string
+	line int
+	// priority decides which step definition is used when many of them match the step
+	priority int
+	// timeout overrides the default timeout of steps
+	timeout time.Duration
 }
 
 type StepTest interface {
@@ -175,6 +423,9 @@ type RuleKey struct{}
 // ScenarioKey is used to store reference to current *msgs.Scenario instance
 type ScenarioKey struct{}
 
+// attachKey is used to store the function attaching data to the currently executed step
+type attachKey struct{}
+
 // Creates a new suites with given configuration and empty steps defined
 func NewSuite(t TestingT, optionClosures ...func(*SuiteOptions)) *Suite {
 	options := NewSuiteOptions()
@@ -187,18 +438,65 @@ func NewSuite(t TestingT, optionClosures ...func(*SuiteOptions)) *Suite {
 		t:              t,
 		steps:          []stepDef{},
 		options:        options,
-		parameterTypes: map[string][]string{},
+		parameterTypes: map[string]*parameterType{},
+		tagExpression:  tagTrue{},
+		newID:          (&msgs.Incrementing{}).NewId,
+	}
+
+	if options.tagExpression != "" {
+		expr, err := parseTagExpression(options.tagExpression)
+		if err != nil {
+			s.t.Fatalf("the tag expression is incorrect: %s", err.Error())
+		} else {
+			s.tagExpression = expr
+		}
 	}
 
-	// see https://github.com/cucumber/cucumber-expressions/blob/main/go/parameter_type_registry.go
-	s.AddParameterTypes(`{int}`, []string{`(-?\d+)`})
-	s.AddParameterTypes(`{float}`, []string{`([-+]?\d*\.?\d+)`})
-	s.AddParameterTypes(`{word}`, []string{`([^\s]+)`})
-	s.AddParameterTypes(`{text}`, []string{`"([^"\\]*(?:\\.[^"\\]*)*)"`, `'([^'\\]*(?:\\.[^'\\]*)*)'`})
+	s.prepareHooks()
+
+	for _, parameterType := range builtinParameterTypes() {
+		s.parameterTypes["{"+parameterType.name+"}"] = parameterType
+	}
 
 	return s
 }
 
+// prepareHooks parses tag expressions of hooks and sorts them by priorities
+func (s *Suite) prepareHooks() {
+	hooks := []struct {
+		hooks  *[]hook
+		kind   hookKind
+		before bool
+	}{
+		{hooks: &s.options.beforeSuite, kind: beforeSuiteHook, before: true},
+		{hooks: &s.options.afterSuite, kind: afterSuiteHook},
+		{hooks: &s.options.beforeFeature, kind: beforeFeatureHook, before: true},
+		{hooks: &s.options.afterFeature, kind: afterFeatureHook},
+		{hooks: &s.options.beforeRule, kind: beforeRuleHook, before: true},
+		{hooks: &s.options.afterRule, kind: afterRuleHook},
+		{hooks: &s.options.beforeScenario, kind: beforeScenarioHook, before: true},
+		{hooks: &s.options.afterScenario, kind: afterScenarioHook},
+		{hooks: &s.options.beforeStep, kind: beforeStepHook, before: true},
+		{hooks: &s.options.afterStep, kind: afterStepHook},
+	}
+
+	for _, h := range hooks {
+		prepared, err := prepareHooks(*h.hooks, h.before)
+		if err != nil {
+			s.t.Fatalf("%s", err.Error())
+
+			continue
+		}
+
+		for i := range prepared {
+			prepared[i].id = s.newID()
+			prepared[i].kind = h.kind
+		}
+
+		*h.hooks = prepared
+	}
+}
+
 // AddParameterTypes adds a list of parameter types that will be used to simplify step definitions.
 //
 // The first argument is the parameter type and the second parameter is a list of regular expressions
@@ -214,8 +512,51 @@ func (s *Suite) AddParameterTypes(from string, to []string) {
 			s.t.Fatalf(`the regular expression for key %s doesn't compile: %s`, from, to)
 		}
 
-		s.parameterTypes[from] = append(s.parameterTypes[from], to)
+		if _, ok := s.parameterTypes[from]; !ok {
+			s.parameterTypes[from] = &parameterType{name: strings.TrimSuffix(strings.TrimPrefix(from, "{"), "}")}
+		}
+
+		s.parameterTypes[from].regexps = append(s.parameterTypes[from].regexps, to)
+	}
+}
+
+// AddParameterType adds a parameter type converting captured values to any type with the transformer.
+// It's supported only by Cucumber Expressions, see WithCucumberExpressions.
+//
+// The first argument is the parameter type and the second parameter is a list of regular expressions
+// matching the value. The transformer is a function accepting the matched text and, optionally,
+// the Context as the first argument. It returns the value passed to the step function and an error:
+//
+//	s.AddParameterType(`{color}`, []string{`red|green|blue`}, func(value string) (Color, error) {
+//		return Color(value), nil
+//	})
+//	s.AddParameterType(`{user}`, []string{`[a-z]+`}, func(ctx gobdd.Context, name string) (*User, error) {
+//		return findUser(ctx, name)
+//	})
+//
+// The name should be in the {name} format. The parameter type should be valid, otherwise will produce an error
+// and stop executing.
+func (s *Suite) AddParameterType(name string, regexps []string, transformer interface{}) {
+	if _, ok := s.parameterTypes[name]; ok {
+		s.t.Fatalf("the parameter type %s is already defined", name)
+
+		return
+	}
+
+	if !parameterTypeName.MatchString(name) {
+		s.t.Fatalf("the parameter type %s is incorrect: the name should be in the {name} format", name)
+
+		return
+	}
+
+	parameterType, err := newParameterType(strings.TrimSuffix(strings.TrimPrefix(name, "{"), "}"), regexps, transformer)
+	if err != nil {
+		s.t.Fatalf("the parameter type %s is incorrect: %s", name, err.Error())
+
+		return
 	}
+
+	s.parameterTypes[name] = parameterType
 }
 
 // AddStep registers a step in the suite.
@@ -228,7 +569,18 @@ func (s *Suite) AddParameterTypes(from string, to []string) {
 //
 //	func myStepFunction(t gobdd.StepTest, ctx gobdd.Context, first int, second int) {
 //	}
-func (s *Suite) AddStep(expr string, step interface{}) {
+//
+// The step can be configured with options, like WithStepPriority.
+func (s *Suite) AddStep(expr string, step interface{}, optionClosures ...func(*StepOptions)) {
+	_, file, line, _ := runtime.Caller(1)
+	options := newStepOptions(optionClosures)
+
+	if s.options.cucumberExpressions {
+		s.addCucumberExpressionStep(expr, step, file, line, options)
+
+		return
+	}
+
 	err := validateStepFunc(step)
 	if err != nil {
 		s.t.Errorf("the step function for step `%s` is incorrect: %s", expr, err.Error())
@@ -237,7 +589,20 @@ func (s *Suite) AddStep(expr string, step interface{}) {
 		return
 	}
 
+	for name, parameterType := range s.parameterTypes {
+		if parameterType.goType != nil && strings.Contains(expr, name) {
+			s.t.Errorf("the parameter type %s of step `%s` is supported only by Cucumber Expressions, "+
+				"see WithCucumberExpressions", name, expr)
+			s.hasStepErrors = true
+
+			return
+		}
+	}
+
+	source := expr
 	exprs := s.applyParameterTypes(expr)
+	// all the expressions created from the source share the ID, they're reported as one step definition
+	id := s.newID()
 
 	for _, expr := range exprs {
 		compiled, err := regexp.Compile(expr)
@@ -248,21 +613,77 @@ func (s *Suite) AddStep(expr string, step interface{}) {
 			return
 		}
 
+		compiled, unanchored := s.anchor(compiled)
+
 		s.steps = append(s.steps, stepDef{
-			expr: compiled,
-			f:    step,
+			id:         id,
+			expr:       compiled,
+			unanchored: unanchored,
+			f:          step,
+			source:     source,
+			file:       file,
+			line:       line,
+			priority:   options.priority,
+			timeout:    options.timeout,
 		})
 	}
 }
 
+func (s *Suite) addCucumberExpressionStep(expr string, step interface{}, file string, line int, options StepOptions) {
+	compiled, parameters, err := compileCucumberExpression(expr, s.parameterTypes)
+	if err != nil {
+		s.t.Errorf("the step function is incorrect: %s", err.Error())
+		s.hasStepErrors = true
+
+		return
+	}
+
+	err = validateStepFunc(step, parameters...)
+	if err != nil {
+		s.t.Errorf("the step function for step `%s` is incorrect: %s", expr, err.Error())
+		s.hasStepErrors = true
+
+		return
+	}
+
+	// the expression without anchors is used to find steps which match only a part of the text
+	unanchored := strings.TrimSuffix(strings.TrimPrefix(compiled.String(), "^"), "$")
+
+	s.steps = append(s.steps, stepDef{
+		id:                 s.newID(),
+		expr:               compiled,
+		unanchored:         regexp.MustCompile(unanchored),
+		f:                  step,
+		source:             expr,
+		cucumberExpression: true,
+		parameters:         parameters,
+		file:               file,
+		line:               line,
+		priority:           options.priority,
+		timeout:            options.timeout,
+	})
+}
+
+// anchor makes the expression match the whole text of a step when the full-text matching is enabled.
+// The original expression is returned as well, so steps matching only a part of the text can be diagnosed.
+func (s *Suite) anchor(expr *regexp.Regexp) (*regexp.Regexp, *regexp.Regexp) {
+	if !s.options.fullTextStepMatching {
+		return expr, nil
+	}
+
+	return regexp.MustCompile(`^(?:` + expr.String() + `)$`), expr
+}
+
 func (s *Suite) applyParameterTypes(expr string) []string {
 	exprs := []string{expr}
 
-	for from, to := range s.parameterTypes {
-		for _, t := range to {
-			if strings.Contains(expr, from) {
-				exprs = append(exprs, s.applyParameterTypes(strings.ReplaceAll(expr, from, t))...)
-			}
+	for from, parameterType := range s.parameterTypes {
+		if parameterType.cucumberExpressionsOnly || !strings.Contains(expr, from) {
+			continue
+		}
+
+		for _, t := range parameterType.regexps {
+			exprs = append(exprs, s.applyParameterTypes(strings.ReplaceAll(expr, from, t))...)
 		}
 	}
 
@@ -279,7 +700,9 @@ func (s *Suite) applyParameterTypes(expr string) []string {
 //
 //	func myStepFunction(t gobdd.StepTest, ctx gobdd.Context, first int, second int) {
 //	}
-func (s *Suite) AddRegexStep(expr *regexp.Regexp, step interface{}) {
+//
+// The step can be configured with options, like WithStepPriority.
+func (s *Suite) AddRegexStep(expr *regexp.Regexp, step interface{}, optionClosures ...func(*StepOptions)) {
 	err := validateStepFunc(step)
 	if err != nil {
 		s.t.Errorf("the step function is incorrect: %s", err.Error())
@@ -288,9 +711,21 @@ func (s *Suite) AddRegexStep(expr *regexp.Regexp, step interface{}) {
 		return
 	}
 
+	_, file, line, _ := runtime.Caller(1)
+	options := newStepOptions(optionClosures)
+
+	compiled, unanchored := s.anchor(expr)
+
 	s.steps = append(s.steps, stepDef{
-		expr: expr,
-		f:    step,
+		id:         s.newID(),
+		expr:       compiled,
+		unanchored: unanchored,
+		f:          step,
+		source:     expr.String(),
+		file:       file,
+		line:       line,
+		priority:   options.priority,
+		timeout:    options.timeout,
 	})
 }
 
@@ -311,15 +746,64 @@ func (s *Suite) Run() {
 		s.t.Parallel()
 	}
 
+	run := &testRun{
+		id:             s.newID(),
+		started:        time.Now(),
+		stepDefs:       s.steps,
+		success:        true,
+		ambiguousSteps: map[string]bool{},
+	}
+
+	for _, hooks := range [][]hook{s.options.beforeScenario, s.options.afterScenario, s.options.beforeStep,
+		s.options.afterStep} {
+		run.hooks = append(run.hooks, hooks...)
+	}
+
+	s.notify(func(l listener) { l.runStarted(run) })
+
+	defer func() {
+		if len(run.undefinedSteps) > 0 {
+			s.t.Logf("there are undefined steps, you can implement them with the snippets below:\n\n%s",
+				undefinedStepsSnippets(run.undefinedSteps, s.options.cucumberExpressions))
+		}
+
+		for _, l := range s.options.listeners {
+			if err := l.runFinished(run); err != nil {
+				s.t.Errorf("the formatter failed: %s", err)
+			}
+		}
+	}()
+
+	// the context of the suite is shared with features, rules and scenarios
+	ctx := withCleanups(NewContext())
+
+	if !s.options.dryRun {
+		defer func() {
+			if err := s.callHooks(afterSuiteHook, s.options.afterSuite, ctx, s.t, nil, HookResult{}); err != nil {
+				run.success = false
+			}
+
+			if err := runCleanups(ctx, s.t); err != nil {
+				run.success = false
+			}
+		}()
+
+		if err := s.callHooks(beforeSuiteHook, s.options.beforeSuite, ctx, s.t, nil, HookResult{}); err != nil {
+			run.success = false
+			run.skipped = true
+		}
+	}
+
 	for _, feature := range features {
-		err = s.executeFeature(feature)
+		err = s.executeFeature(run, ctx, feature)
 		if err != nil {
+			run.success = false
 			s.t.Fail()
 		}
 	}
 }
 
-func (s *Suite) executeFeature(feature feature) error {
+func (s *Suite) executeFeature(run *testRun, ctx Context, feature feature) error {
 	f, err := feature.Open()
 	if err != nil {
 		return err
@@ -329,9 +813,12 @@ func (s *Suite) executeFeature(feature feature) error {
 		defer closer.Close()
 	}
 
-	featureIO := bufio.NewReader(f)
+	source, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
 
-	doc, err := gherkin.ParseGherkinDocument(featureIO, (&msgs.Incrementing{}).NewId)
+	doc, err := gherkin.ParseGherkinDocument(bytes.NewReader(source), s.newID)
 	if err != nil {
 		s.t.Fatalf("error while loading document: %s\n", err)
 	}
@@ -340,18 +827,64 @@ func (s *Suite) executeFeature(feature feature) error {
 		return nil
 	}
 
-	return s.runFeature(doc.Feature)
+	doc.Uri = feature.URI()
+
+	s.runFeature(run, ctx, &featureDocument{
+		uri:      doc.Uri,
+		source:   source,
+		document: doc,
+		pickles:  gherkin.Pickles(*doc, doc.Uri, s.newID),
+	})
+
+	return nil
 }
 
-func (s *Suite) runFeature(feature *msgs.Feature) error {
+func (s *Suite) runFeature(run *testRun, ctx Context, doc *featureDocument) {
+	feature := doc.document.Feature
+
+	s.notify(func(l listener) { l.featureStarted(doc) })
+	defer s.notify(func(l listener) { l.featureFinished(doc) })
+
 	if s.shouldSkipFeatureOrRule(feature.Tags) {
 		s.t.Logf("the feature (%s) is ignored ", feature.Name)
-		return nil
+		s.skipFeature(doc)
+
+		return
+	}
+
+	if run.skipped {
+		s.skipFeature(doc)
+
+		return
 	}
 
-	hasErrors := false
+	featureCtx := withCleanups(ctx.Clone())
+	featureCtx.Set(FeatureKey{}, feature)
 
 	s.t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(feature.Keyword), feature.Name), func(t *testing.T) {
+		// hooks aren't called when all scenarios of the feature are filtered out
+		if !s.options.dryRun && s.featureHasTestCasesToRun(feature) {
+			defer func() {
+				err := s.callHooks(afterFeatureHook, s.options.afterFeature, featureCtx, t, feature.Tags, HookResult{})
+				if err != nil {
+					run.success = false
+				}
+
+				if err := runCleanups(featureCtx, t); err != nil {
+					run.success = false
+				}
+			}()
+
+			// scenarios of the feature whose before hook failed are reported as skipped
+			err := s.callHooks(beforeFeatureHook, s.options.beforeFeature, featureCtx, t, feature.Tags, HookResult{})
+			if err != nil {
+				run.success = false
+				s.skipFeature(doc)
+
+				return
+			}
+		}
+
 		backgrounds := []*msgs.Background{}
 
 		for _, child := range feature.Children {
@@ -360,83 +893,72 @@ func (s *Suite) runFeature(feature *msgs.Feature) error {
 			}
 
 			if rule := child.Rule; rule != nil {
-				s.runRule(feature, rule, backgrounds, t)
+				s.runRule(run, featureCtx, doc, rule, backgrounds, t)
 			}
 			if scenario := child.Scenario; scenario != nil {
-				ctx := NewContext()
-				ctx.Set(FeatureKey{}, feature)
-				s.runScenario(ctx, scenario, backgrounds, t, feature.Tags)
+				s.runScenario(run, featureCtx.Clone(), doc, nil, scenario, backgrounds, t, feature.Tags)
 			}
 		}
 	})
-
-	if hasErrors {
-		return errors.New("the feature contains errors")
-	}
-
-	return nil
 }
 
-func (s *Suite) getOutlineStep(
-	steps []*msgs.Step,
-	examples []*msgs.Examples) []*msgs.Step {
-	stepsList := make([][]*msgs.Step, len(steps))
+// skipFeature notifies listeners about all scenarios of the ignored feature
+func (s *Suite) skipFeature(doc *featureDocument) {
+	backgrounds := []*msgs.Background{}
 
-	for i, outlineStep := range steps {
-		for _, example := range examples {
-			stepsList[i] = append(stepsList[i], s.stepsFromExamples(outlineStep, example)...)
+	for _, child := range doc.document.Feature.Children {
+		if child.Background != nil {
+			backgrounds = append(backgrounds, child.Background)
 		}
-	}
 
-	var newSteps []*msgs.Step
+		if rule := child.Rule; rule != nil {
+			ruleBackgrounds := []*msgs.Background{}
+			ruleBackgrounds = append(ruleBackgrounds, backgrounds...)
 
-	if len(stepsList) == 0 {
-		return newSteps
-	}
+			s.notify(func(l listener) { l.ruleStarted(doc, rule) })
+			s.skipRule(doc, rule, ruleBackgrounds)
+			s.notify(func(l listener) { l.ruleFinished(doc, rule) })
+		}
 
-	for ei := range examples {
-		for ci := range examples[ei].TableBody {
-			for si := range steps {
-				newSteps = append(newSteps, stepsList[si][ci])
-			}
+		if child.Scenario != nil {
+			s.skipScenario(doc, nil, child.Scenario, backgrounds)
 		}
 	}
-
-	return newSteps
 }
 
-func (s *Suite) stepsFromExamples(
-	sourceStep *msgs.Step,
-	example *msgs.Examples) []*msgs.Step {
-	steps := []*msgs.Step{}
-
-	placeholdersValues := []string{}
+// skipRule notifies listeners about all scenarios of the ignored rule
+func (s *Suite) skipRule(doc *featureDocument, rule *msgs.Rule, backgrounds []*msgs.Background) {
+	for _, child := range rule.Children {
+		if child.Background != nil {
+			backgrounds = append(backgrounds, child.Background)
+		}
 
-	if example.TableHeader != nil {
-		placeholders := example.TableHeader.Cells
-		for _, placeholder := range placeholders {
-			ph := "<" + placeholder.Value + ">"
-			placeholdersValues = append(placeholdersValues, ph)
+		if child.Scenario != nil {
+			s.skipScenario(doc, rule, child.Scenario, backgrounds)
 		}
 	}
+}
 
-	text := sourceStep.Text
-
-	for _, row := range example.TableBody {
-		// iterate over the cells and update the text
-		stepText, expr := s.stepFromExample(text, row, placeholdersValues)
+// stepsFromExampleRow returns steps of the scenario outline with placeholders replaced by values from the row
+func (s *Suite) stepsFromExampleRow(
+	sourceSteps []*msgs.Step,
+	example *msgs.Examples,
+	row *msgs.TableRow) []*msgs.Step {
+	placeholders := []string{}
 
-		// find step definition for the new step
-		def, err := s.findStepDef(stepText)
-		if err != nil {
-			continue
+	if example.TableHeader != nil {
+		for _, placeholder := range example.TableHeader.Cells {
+			placeholders = append(placeholders, "<"+placeholder.Value+">")
 		}
+	}
+
+	steps := make([]*msgs.Step, 0, len(sourceSteps))
 
-		// add the step to the list
-		s.AddStep(expr, def.f)
+	for _, sourceStep := range sourceSteps {
+		stepText := stepFromExample(sourceStep.Text, row, placeholders)
 
 		// clone a step
-		step := &msgs.Step{
+		steps = append(steps, &msgs.Step{
 			Location:    sourceStep.Location,
 			Keyword:     sourceStep.Keyword,
 			Text:        stepText,
@@ -444,259 +966,999 @@ func (s *Suite) stepsFromExamples(
 			DocString:   sourceStep.DocString,
 			DataTable:   sourceStep.DataTable,
 			Id:          sourceStep.Id,
-		}
-
-		steps = append(steps, step)
+		})
 	}
 
 	return steps
 }
 
-func (s *Suite) stepFromExample(
-	stepName string,
-	row *msgs.TableRow, placeholders []string) (string, string) {
-	expr := stepName
-
+// stepFromExample replaces placeholders in the text of the step with values from the row
+func stepFromExample(stepName string, row *msgs.TableRow, placeholders []string) string {
 	for i, ph := range placeholders {
-		t := getRegexpForVar(row.Cells[i].Value)
-		expr = strings.ReplaceAll(expr, ph, t)
 		stepName = strings.ReplaceAll(stepName, ph, row.Cells[i].Value)
 	}
 
-	return stepName, expr
-}
-
-func (s *Suite) callBeforeScenarios(ctx Context) {
-	for _, f := range s.options.beforeScenario {
-		f(ctx)
-	}
-}
-
-func (s *Suite) callAfterScenarios(ctx Context) {
-	for _, f := range s.options.afterScenario {
-		f(ctx)
-	}
-}
-
-func (s *Suite) callBeforeSteps(ctx Context) {
-	for _, f := range s.options.beforeStep {
-		f(ctx)
-	}
+	return stepName
 }
 
-func (s *Suite) callAfterSteps(ctx Context) {
-	for _, f := range s.options.afterStep {
-		f(ctx)
-	}
-}
-func (s *Suite) runRule(feature *msgs.Feature, rule *msgs.Rule,
+func (s *Suite) runRule(run *testRun, ctx Context, doc *featureDocument, rule *msgs.Rule,
 	backgrounds []*msgs.Background, t *testing.T) {
+	feature := doc.document.Feature
 	ruleTags := feature.Tags
 	ruleTags = append(ruleTags, rule.Tags...)
 
+	ruleBackgrounds := []*msgs.Background{}
+	ruleBackgrounds = append(ruleBackgrounds, backgrounds...)
+
+	s.notify(func(l listener) { l.ruleStarted(doc, rule) })
+	defer s.notify(func(l listener) { l.ruleFinished(doc, rule) })
+
 	if s.shouldSkipFeatureOrRule(ruleTags) {
 		s.t.Logf("the rule (%s) is ignored ", feature.Name)
+		s.skipRule(doc, rule, ruleBackgrounds)
+
 		return
 	}
 
-	ruleBackgrounds := []*msgs.Background{}
-	ruleBackgrounds = append(ruleBackgrounds, backgrounds...)
+	ruleCtx := withCleanups(ctx.Clone())
+	ruleCtx.Set(RuleKey{}, rule)
 
 	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(rule.Keyword), rule.Name), func(t *testing.T) {
+		if !s.options.dryRun && s.ruleHasTestCasesToRun(rule, ruleTags) {
+			defer func() {
+				if err := s.callHooks(afterRuleHook, s.options.afterRule, ruleCtx, t, ruleTags, HookResult{}); err != nil {
+					run.success = false
+				}
+
+				if err := runCleanups(ruleCtx, t); err != nil {
+					run.success = false
+				}
+			}()
+
+			// scenarios of the rule whose before hook failed are reported as skipped
+			if err := s.callHooks(beforeRuleHook, s.options.beforeRule, ruleCtx, t, ruleTags, HookResult{}); err != nil {
+				run.success = false
+				s.skipRule(doc, rule, ruleBackgrounds)
+
+				return
+			}
+		}
+
 		for _, ruleChild := range rule.Children {
 			if ruleChild.Background != nil {
 				ruleBackgrounds = append(ruleBackgrounds, ruleChild.Background)
 			}
 			if scenario := ruleChild.Scenario; scenario != nil {
-				ctx := NewContext()
-				ctx.Set(FeatureKey{}, feature)
-				ctx.Set(RuleKey{}, rule)
-				s.runScenario(ctx, scenario, ruleBackgrounds, t, ruleTags)
+				s.runScenario(run, ruleCtx.Clone(), doc, rule, scenario, ruleBackgrounds, t, ruleTags)
 			}
 		}
 	})
 }
-func (s *Suite) runScenario(ctx Context, scenario *msgs.Scenario,
-	backgrounds []*msgs.Background, t *testing.T, parentTags []*msgs.Tag) {
-	if s.shouldSkipScenario(append(parentTags, scenario.Tags...)) {
-		t.Logf("Skipping scenario %s", scenario.Name)
-		return
-	}
 
-	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(scenario.Keyword), scenario.Name), func(t *testing.T) {
-		// NOTE consider passing t as argument to scenario hooks
-		ctx.Set(ScenarioKey{}, scenario)
-		ctx.Set(TestingTKey{}, t)
-		defer ctx.Set(TestingTKey{}, nil)
+// featureHasTestCasesToRun tells whether at least one test case of the feature is selected to run
+func (s *Suite) featureHasTestCasesToRun(feature *msgs.Feature) bool {
+	for _, child := range feature.Children {
+		if child.Rule != nil {
+			ruleTags := make([]*msgs.Tag, 0, len(feature.Tags)+len(child.Rule.Tags))
+			ruleTags = append(ruleTags, feature.Tags...)
+			ruleTags = append(ruleTags, child.Rule.Tags...)
 
-		s.callBeforeScenarios(ctx)
-		defer s.callAfterScenarios(ctx)
+			if !s.shouldSkipFeatureOrRule(ruleTags) && s.ruleHasTestCasesToRun(child.Rule, ruleTags) {
+				return true
+			}
+		}
 
-		if len(backgrounds) > 0 {
-			steps := s.getBackgroundSteps(backgrounds)
-			s.runSteps(ctx, t, steps)
+		if child.Scenario != nil && s.scenarioHasTestCasesToRun(child.Scenario, feature.Tags) {
+			return true
 		}
-		steps := scenario.Steps
-		if examples := scenario.Examples; len(examples) > 0 {
-			c := ctx.Clone()
-			steps = s.getOutlineStep(scenario.Steps, examples)
-			s.runSteps(c, t, steps)
-		} else {
-			c := ctx.Clone()
-			s.runSteps(c, t, steps)
+	}
+
+	return false
+}
+
+// ruleHasTestCasesToRun tells whether at least one test case of the rule is selected to run
+func (s *Suite) ruleHasTestCasesToRun(rule *msgs.Rule, ruleTags []*msgs.Tag) bool {
+	for _, child := range rule.Children {
+		if child.Scenario != nil && s.scenarioHasTestCasesToRun(child.Scenario, ruleTags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scenarioHasTestCasesToRun tells whether the scenario or at least one row of its Examples is selected to run
+func (s *Suite) scenarioHasTestCasesToRun(scenario *msgs.Scenario, parentTags []*msgs.Tag) bool {
+	tags := make([]*msgs.Tag, 0, len(parentTags)+len(scenario.Tags))
+	tags = append(tags, parentTags...)
+	tags = append(tags, scenario.Tags...)
+
+	if len(scenario.Examples) == 0 {
+		return !s.shouldSkipScenario(tags)
+	}
+
+	for _, example := range scenario.Examples {
+		exampleTags := make([]*msgs.Tag, 0, len(tags)+len(example.Tags))
+		exampleTags = append(exampleTags, tags...)
+		exampleTags = append(exampleTags, example.Tags...)
+
+		if len(example.TableBody) > 0 && !s.shouldSkipScenario(exampleTags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runScenario executes the scenario or, in case of a scenario outline,
+// every row of its Examples as a separate test case
+func (s *Suite) runScenario(run *testRun, ctx Context, doc *featureDocument, rule *msgs.Rule, scenario *msgs.Scenario,
+	backgrounds []*msgs.Background, t *testing.T, parentTags []*msgs.Tag) {
+	testCases := []*testCase{}
+
+	for _, tc := range s.scenarioTestCases(doc, rule, scenario, backgrounds) {
+		tags := make([]*msgs.Tag, 0, len(parentTags)+len(tc.tags))
+		tags = append(tags, parentTags...)
+		tags = append(tags, tc.tags...)
+
+		if !s.shouldSkipScenario(tags) {
+			tc.allTags = tags
+			s.addHookSteps(tc)
+			testCases = append(testCases, tc)
+		} else if s.isIgnored(tags) {
+			s.skipTestCase(tc)
+		}
+	}
+
+	// an outline is executed as long as at least one of its Examples blocks is selected
+	if len(testCases) == 0 {
+		t.Logf("Skipping scenario %s", scenario.Name)
+		return
+	}
+
+	name := fmt.Sprintf("%s %s", strings.TrimSpace(scenario.Keyword), scenario.Name)
+
+	if len(scenario.Examples) == 0 {
+		t.Run(name, func(t *testing.T) {
+			s.runTestCaseWithRetries(run, ctx, t, testCases[0])
+		})
+
+		return
+	}
+
+	t.Run(name, func(t *testing.T) {
+		for _, tc := range testCases {
+			tc := tc
+
+			t.Run(tc.exampleName, func(t *testing.T) {
+				s.runTestCaseWithRetries(run, ctx, t, tc)
+			})
 		}
 	})
 }
 
-func (s *Suite) runSteps(ctx Context, t *testing.T, steps []*msgs.Step) {
+// scenarioTestCases creates test cases for the scenario: one for a regular scenario
+// and one for every row of Examples of a scenario outline
+func (s *Suite) scenarioTestCases(doc *featureDocument, rule *msgs.Rule, scenario *msgs.Scenario,
+	backgrounds []*msgs.Background) []*testCase {
+	if len(scenario.Examples) == 0 {
+		tc := s.newTestCase(doc, rule, scenario, nil, nil, backgrounds, scenario.Steps)
+		tc.tags = scenario.Tags
+
+		return []*testCase{tc}
+	}
+
+	testCases := []*testCase{}
+
+	for ei, example := range scenario.Examples {
+		tags := make([]*msgs.Tag, 0, len(scenario.Tags)+len(example.Tags))
+		tags = append(tags, scenario.Tags...)
+		tags = append(tags, example.Tags...)
+
+		for ri, row := range example.TableBody {
+			steps := s.stepsFromExampleRow(scenario.Steps, example, row)
+			tc := s.newTestCase(doc, rule, scenario, example, row, backgrounds, steps)
+			tc.tags = tags
+			tc.exampleName = fmt.Sprintf("%s #%d.%d", strings.TrimSpace(example.Keyword), ei+1, ri+1)
+
+			testCases = append(testCases, tc)
+		}
+	}
+
+	return testCases
+}
+
+// skipScenario notifies listeners about test cases of the scenario from the ignored feature or rule
+func (s *Suite) skipScenario(doc *featureDocument, rule *msgs.Rule, scenario *msgs.Scenario,
+	backgrounds []*msgs.Background) {
+	for _, tc := range s.scenarioTestCases(doc, rule, scenario, backgrounds) {
+		s.skipTestCase(tc)
+	}
+}
+
+func (s *Suite) skipTestCase(tc *testCase) {
+	tc.status = msgs.TestStepResultStatus_SKIPPED
+	s.notify(func(l listener) { l.testCaseSkipped(tc) })
+}
+
+// newTestCase creates a test case with steps of given backgrounds followed by given steps
+// and resolves step definitions for all of them
+func (s *Suite) newTestCase(doc *featureDocument, rule *msgs.Rule, scenario *msgs.Scenario,
+	example *msgs.Examples, row *msgs.TableRow, backgrounds []*msgs.Background, steps []*msgs.Step) *testCase {
+	tc := &testCase{
+		id:       s.newID(),
+		uri:      doc.uri,
+		feature:  doc.document.Feature,
+		rule:     rule,
+		scenario: scenario,
+		examples: example,
+		row:      row,
+		pickle:   doc.pickle(scenario, row),
+	}
+
+	pickleSteps := map[string]*msgs.PickleStep{}
+
+	if tc.pickle != nil {
+		for _, pickleStep := range tc.pickle.Steps {
+			pickleSteps[pickleStep.AstNodeIds[0]] = pickleStep
+		}
+	}
+
+	newStep := func(step *msgs.Step, background *msgs.Background) *testStep {
+		ts := &testStep{
+			id:         s.newID(),
+			step:       step,
+			pickleStep: pickleSteps[step.Id],
+			background: background,
+		}
+
+		if def, ambiguous, err := s.findStepDef(step.Text); err == nil {
+			ts.def = &def
+			ts.ambiguous = ambiguous
+		} else {
+			ts.partial = s.partiallyMatchingStepDefs(step.Text)
+		}
+
+		return ts
+	}
+
+	for _, background := range backgrounds {
+		for _, step := range background.Steps {
+			tc.steps = append(tc.steps, newStep(step, background))
+		}
+	}
+
 	for _, step := range steps {
-		s.runStep(ctx, t, step)
+		tc.steps = append(tc.steps, newStep(step, nil))
 	}
+
+	return tc
 }
 
-func (s *Suite) runStep(ctx Context, t *testing.T, step *msgs.Step) {
+// addHookSteps adds scenario and step hooks matching tags to the test case, hooks are not called in dry run
+func (s *Suite) addHookSteps(tc *testCase) {
+	if s.options.dryRun {
+		return
+	}
+
+	tags := tagNames(tc.allTags)
+
+	tc.beforeHooks = s.newHookSteps(s.options.beforeScenario, tags, nil)
+	tc.afterHooks = s.newHookSteps(s.options.afterScenario, tags, nil)
+
+	for _, step := range tc.steps {
+		step.beforeHooks = s.newHookSteps(s.options.beforeStep, tags, step)
+		step.afterHooks = s.newHookSteps(s.options.afterStep, tags, step)
+	}
+}
+
+func (s *Suite) runTestCase(run *testRun, ctx Context, t TestingT, tc *testCase) {
+	tc.startedID = s.newID()
+	tc.started = time.Now()
+	tc.status = msgs.TestStepResultStatus_PASSED
+
+	s.notify(func(l listener) { l.testCaseStarted(tc) })
+
+	executed := 0
+	// after scenario hooks are called only when before scenario hooks have been called
+	hooksCalled := false
+
+	ctx = withCleanups(ctx)
+
+	// the test case is finished even if it's stopped by t.FailNow, t.SkipNow or a panic
 	defer func() {
 		if r := recover(); r != nil {
-			t.Error(r)
+			err := fmt.Errorf("%+v", r)
+			t.Error(err.Error())
+			tc.failHook(err)
+		}
+
+		// steps which were not executed because the test case has been stopped,
+		// undefined ones are reported as such, so all of them can be implemented at once
+		for i, step := range tc.steps {
+			s.skipHookSteps(tc, step.beforeHooks)
+
+			switch {
+			case i < executed:
+			case step.def == nil:
+				s.notifyStep(tc, step, stepResult{status: msgs.TestStepResultStatus_UNDEFINED, err: errUndefinedStep(step)})
+			default:
+				s.notifyStep(tc, step, stepResult{status: msgs.TestStepResultStatus_SKIPPED})
+			}
+
+			s.skipHookSteps(tc, step.afterHooks)
+		}
+
+		// undefined steps are the same in every attempt of the retried test case
+		for _, step := range tc.steps {
+			if step.result.status == msgs.TestStepResultStatus_UNDEFINED && tc.attempt == 0 {
+				run.undefinedSteps = append(run.undefinedSteps, step.step)
+			}
+		}
+
+		if tc.status == msgs.TestStepResultStatus_PASSED && t.Failed() {
+			tc.status = msgs.TestStepResultStatus_FAILED
 		}
+
+		if hooksCalled {
+			tc.failHook(s.callHookSteps(tc, tc.afterHooks, ctx, t, tc.hookResult()))
+		}
+
+		s.skipHookSteps(tc, tc.beforeHooks)
+		s.skipHookSteps(tc, tc.afterHooks)
+
+		tc.failHook(runCleanups(ctx, t))
+
+		ctx.Set(TestingTKey{}, nil)
+
+		passed := tc.status == msgs.TestStepResultStatus_PASSED || tc.status == msgs.TestStepResultStatus_SKIPPED
+		tc.willBeRetried = !passed && tc.attempt < tc.retries
+		tc.flaky = passed && tc.attempt > 0
+
+		if !passed && !tc.willBeRetried {
+			run.success = false
+		}
+
+		tc.duration = time.Since(tc.started)
+		s.notify(func(l listener) { l.testCaseFinished(tc) })
 	}()
 
-	def, err := s.findStepDef(step.Text)
+	timeout, err := s.scenarioTimeout(tc.allTags)
 	if err != nil {
-		t.Fatalf("cannot find step definition for step: %s%s", step.Keyword, step.Text)
+		t.Fatal(err.Error())
+	}
+
+	tc.timeout = timeout
+
+	ctx.Set(ScenarioKey{}, tc.scenario)
+	ctx.Set(TestingTKey{}, testingTOf(t))
+
+	if !s.options.dryRun {
+		hooksCalled = true
+
+		// steps of the scenario whose before hook failed or skipped the scenario are reported as skipped
+		err := s.callHookSteps(tc, tc.beforeHooks, ctx, t, HookResult{})
+
+		switch {
+		case errors.Is(err, ErrSkip):
+			tc.status = msgs.TestStepResultStatus_SKIPPED
+			t.SkipNow()
+
+			return
+		case err != nil:
+			tc.failHook(err)
+
+			return
+		}
+	}
+
+	// background steps share the context with scenario hooks, the scenario steps work on its copy
+	stepsCtx, cloned := ctx, false
+
+	for i, step := range tc.steps {
+		if step.background == nil && !cloned {
+			stepsCtx, cloned = ctx.Clone(), true
+		}
+
+		executed = i + 1
+
+		// steps following the one which didn't pass are reported as skipped (or undefined) and not executed
+		if !s.runStep(run, stepsCtx, t, tc, step) {
+			return
+		}
+	}
+}
+
+// warnAboutAmbiguousStep logs the warning about the ambiguous step once for the text of the step,
+// no matter how many times it's executed
+func (s *Suite) warnAboutAmbiguousStep(run *testRun, step *testStep, err error) {
+	if run.ambiguousSteps[step.step.Text] {
+		return
+	}
+
+	run.ambiguousSteps[step.step.Text] = true
+	s.t.Logf("%s\nthe step definition %s (%s:%d) is used", err, step.def.source, step.def.file, step.def.line)
+}
+
+// runStep executes the step, it returns false when the step didn't pass, so the rest of the scenario
+// shouldn't be executed
+func (s *Suite) runStep(run *testRun, ctx Context, t TestingT, tc *testCase, step *testStep) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Error(r)
+
+			ok = false
+		}
+	}()
+
+	if s.options.dryRun {
+		s.dryRunStep(run, t, tc, step)
+
+		return true
+	}
+
+	if step.def == nil {
+		err := errUndefinedStep(step)
+		s.notifyStep(tc, step, stepResult{status: msgs.TestStepResultStatus_UNDEFINED, err: err})
+		t.Fatal(err.Error())
+	}
+
+	if len(step.ambiguous) > 0 {
+		err := errAmbiguousStep(step, step.ambiguous)
+		if s.options.failOnAmbiguousSteps {
+			s.notifyStep(tc, step, stepResult{status: msgs.TestStepResultStatus_AMBIGUOUS, err: err})
+			t.Fatal(err.Error())
+		}
+
+		s.warnAboutAmbiguousStep(run, step, err)
+	}
+
+	params := step.def.params(step.step, s.options.docStringDecoders)
+	timeout, scenarioTimeout := s.stepTimeout(tc, step)
+
+	var (
+		recorder *stepRecorder
+		result   stepResult
+		// hookErr is the error of the first failed step hook, hooks are reported separately from the step
+		hookErr error
+	)
+
+	passed := runSubtest(t, fmt.Sprintf("%s %s", strings.TrimSpace(step.step.Keyword), step.step.Text), func(t TestingT) {
+		// NOTE consider passing t as argument to step hooks
+		ctx.Set(TestingTKey{}, testingTOf(t))
+		defer ctx.Set(TestingTKey{}, nil)
+
+		recorder = &stepRecorder{TestingT: t}
+
+		ctx.Set(attachKey{}, func(data []byte, mediaType string) {
+			if !recorder.active() {
+				// the step has timed out, the following steps may be executed already
+				return
+			}
+
+			a := &attachment{data: data, mediaType: mediaType, timestamp: time.Now()}
+			step.attachments = append(step.attachments, a)
+			s.notify(func(l listener) { l.attached(tc, step, a) })
+		})
+		defer ctx.Set(attachKey{}, nil)
+
+		// hooks are reported to the test, but not to the recorder, so their failures don't change the result
+		// of the step. The step whose before hook failed is not executed and it's reported as skipped.
+		err := s.callHookSteps(tc, step.beforeHooks, ctx, t, HookResult{})
+		if err != nil && !errors.Is(err, ErrSkip) {
+			hookErr = err
+		}
+
+		s.notify(func(l listener) { l.stepStarted(tc, step) })
+
+		start := time.Now()
+
+		defer func() {
+			result = stepResultOf(recorder, !t.Failed(), time.Since(start))
+			if hookErr != nil {
+				result = stepResult{status: msgs.TestStepResultStatus_SKIPPED, duration: result.duration}
+			}
+
+			tc.finishStep(step, result)
+			s.notify(func(l listener) { l.stepFinished(tc, step, result) })
+
+			hookResult := HookResult{Status: result.status, Err: result.err, Duration: result.duration}
+			if result.err != nil {
+				hookResult.FailedStep = step.step
+			}
+
+			err := s.callHookSteps(tc, step.afterHooks, ctx, t, hookResult)
+			if hookErr == nil && err != nil && !errors.Is(err, ErrSkip) {
+				hookErr = err
+			}
+		}()
+
+		switch {
+		case hookErr != nil:
+			return
+		case errors.Is(err, ErrSkip):
+			recorder.SkipNow()
+		}
+
+		if scenarioTimeout && timeout <= 0 {
+			recorder.Fatal(errStepTimeout(tc, step, 0, true, "").Error())
+		}
+
+		var timeoutErr *timeoutError
+
+		err = step.def.run(ctx, recorder, params, timeout)
+
+		switch {
+		case errors.As(err, &timeoutErr):
+			err = errStepTimeout(tc, step, timeoutErr.timeout, scenarioTimeout, timeoutErr.stack)
+			recorder.stop(err)
+			t.Fatal(err.Error())
+		case err != nil:
+			recorder.Fatal(errStepArguments(tc, step, err).Error())
+		}
+	})
+
+	if recorder == nil {
+		// steps filtered out by the -run flag are reported as skipped, but they don't stop the scenario
+		s.notifyStep(tc, step, stepResultOf(nil, passed, 0))
+
+		return true
+	}
+
+	tc.failHook(hookErr)
+
+	return result.status == msgs.TestStepResultStatus_PASSED && hookErr == nil
+}
+
+// stepResultOf returns the result of the step executed with the recorder, passed tells whether the subtest passed
+func stepResultOf(recorder *stepRecorder, passed bool, duration time.Duration) stepResult {
+	result := stepResult{
+		status:   msgs.TestStepResultStatus_PASSED,
+		duration: duration,
+	}
+
+	switch {
+	case recorder != nil && recorder.pending:
+		result.status = msgs.TestStepResultStatus_PENDING
+		result.err = recorder.err()
+	case !passed:
+		result.status = msgs.TestStepResultStatus_FAILED
+		result.err = recorder.err()
+	case recorder == nil || recorder.skipped:
+		// the step has been skipped by the step itself or filtered out by the -run flag
+		result.status = msgs.TestStepResultStatus_SKIPPED
+	}
+
+	return result
+}
+
+func errUndefinedStep(step *testStep) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "cannot find step definition for step: %s%s", step.step.Keyword, step.step.Text)
+
+	for _, def := range step.partial {
+		fmt.Fprintf(&sb, "\n\tthe step definition %s (%s:%d) matches only a part of the step's text, "+
+			"but it has to match the whole text", def.source, def.file, def.line)
 	}
 
-	matches := def.expr.FindSubmatch([]byte(step.Text))[1:]
+	return errors.New(sb.String())
+}
+
+// errStepArguments describes the step whose params cannot be converted to arguments of the step function
+func errStepArguments(tc *testCase, step *testStep, err error) error {
+	return fmt.Errorf("the step %s%s (%s:%d) cannot be executed: %w",
+		step.step.Keyword, step.step.Text, tc.uri, step.step.Location.Line, err)
+}
+
+func errAmbiguousStep(step *testStep, defs []stepDef) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "the step %s%s matches many step definitions:", step.step.Keyword, step.step.Text)
+
+	for _, def := range defs {
+		fmt.Fprintf(&sb, "\n\t%s (%s:%d)", def.source, def.file, def.line)
+	}
+
+	return errors.New(sb.String())
+}
+
+// notifyStep notifies listeners about the step which has not been executed
+func (s *Suite) notifyStep(tc *testCase, step *testStep, result stepResult) {
+	tc.finishStep(step, result)
+	s.notify(func(l listener) { l.stepStarted(tc, step) })
+	s.notify(func(l listener) { l.stepFinished(tc, step, result) })
+}
+
+func (s *Suite) notify(f func(l listener)) {
+	for _, l := range s.options.listeners {
+		f(l)
+	}
+}
+
+// params returns values captured from the step's text followed by the doc string or the data table, if any.
+// Doc strings are decoded with the given decoders, depending on their media types.
+func (def *stepDef) params(step *msgs.Step, decoders map[string]func(data []byte, v interface{}) error) []interface{} {
+	matches := def.matchedGroups(step.Text)
 	params := make([]interface{}, 0, len(matches)) // defining the slices capacity instead of the length to use append
 	for _, m := range matches {
-		params = append(params, m)
+		switch {
+		case m.parameterType != nil:
+			params = append(params, parameterValue{parameterType: m.parameterType, text: m.value})
+		case m.matched:
+			params = append(params, []byte(m.value))
+		default:
+			params = append(params, []byte(nil))
+		}
 	}
 
 	if step.DocString != nil {
-		params = append(params, step.DocString.Content)
+		params = append(params, docStringValue{
+			docString: DocString{Content: step.DocString.Content, MediaType: step.DocString.MediaType},
+			decoders:  decoders,
+		})
 	}
 	if step.DataTable != nil {
 		params = append(params, *step.DataTable)
 	}
 
-	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(step.Keyword), step.Text), func(t *testing.T) {
-		// NOTE consider passing t as argument to step hooks
-		ctx.Set(TestingTKey{}, t)
-		defer ctx.Set(TestingTKey{}, nil)
+	return params
+}
 
-		s.callBeforeSteps(ctx)
-		defer s.callAfterSteps(ctx)
+// matchedGroup is a value captured by the step definition from the step's text
+type matchedGroup struct {
+	start   int
+	value   string
+	matched bool
+	// parameterType is set for parameters of Cucumber Expressions
+	parameterType *parameterType
+}
 
-		def.run(ctx, t, params)
-	})
+// matchedGroups returns values captured from the step's text: one for every group of the regular expression
+// or one for every parameter of the Cucumber Expression
+func (def *stepDef) matchedGroups(text string) []matchedGroup {
+	indexes := def.expr.FindStringSubmatchIndex(text)
+	if indexes == nil {
+		return nil
+	}
+
+	group := func(i int) matchedGroup {
+		if indexes[2*i] < 0 {
+			return matchedGroup{}
+		}
+
+		return matchedGroup{start: indexes[2*i], value: text[indexes[2*i]:indexes[2*i+1]], matched: true}
+	}
+
+	groups := []matchedGroup{}
+
+	if def.parameters == nil {
+		for i := 1; i <= def.expr.NumSubexp(); i++ {
+			groups = append(groups, group(i))
+		}
+
+		return groups
+	}
+
+	for _, parameter := range def.parameters {
+		g := matchedGroup{}
+
+		// only one of the parameter type's regexps matches
+		for _, i := range parameter.groups {
+			if g = group(i); g.matched {
+				break
+			}
+		}
+
+		g.parameterType = parameter.parameterType
+		groups = append(groups, g)
+	}
+
+	return groups
+}
+
+// arguments converts params to arguments of the step function, except for the StepTest and the context.
+// The context is passed to transformers of parameter types, it's nil in dry run.
+func (def *stepDef) arguments(ctx *Context, params []interface{}) ([]reflect.Value, error) {
+	d := reflect.ValueOf(def.f)
+	leading := contextArguments(d.Type())
+
+	if len(params)+leading != d.Type().NumIn() {
+		return nil, fmt.Errorf("the step function %s accepts %d arguments but %d received",
+			d.String(),
+			d.Type().NumIn(),
+			len(params)+leading)
+	}
+
+	arguments := make([]reflect.Value, 0, len(params))
+
+	for i, v := range params {
+		inType := d.Type().In(i + leading)
+
+		var (
+			argument reflect.Value
+			err      error
+		)
+
+		switch value := v.(type) {
+		case parameterValue:
+			argument, err = value.convert(ctx, inType)
+		case docStringValue:
+			argument, err = value.convert(inType, ctx != nil)
+		default:
+			argument, err = paramType(v, inType)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, argument)
+	}
+
+	return arguments, nil
 }
 
-func (def *stepDef) run(ctx Context, t TestingT, params []interface{}) { // nolint:interfacer
+// run calls the step function. Errors converting params to arguments are returned, the step function isn't called
+// in such a case. The error returned by the step function is reported with reportStepError, the returned
+// context.Context is passed to following steps. When the timeout is set and exceeded, the context.Context passed
+// to the step is cancelled and the timeoutError is returned, but the step function may be still running.
+// That's why, with the timeout, the step function works on a copy of the context, whose values are copied back
+// only when the function finishes in time.
+func (def *stepDef) run(ctx Context, t TestingT, params []interface{}, // nolint:interfacer
+	timeout time.Duration) error {
 	defer func() {
 		if r := recover(); r != nil {
 			t.Errorf("%+v", r)
 		}
 	}()
 
+	arguments, err := def.arguments(&ctx, params)
+	if err != nil {
+		return err
+	}
+
 	d := reflect.ValueOf(def.f)
-	if len(params)+contextArgumentsNumber != d.Type().NumIn() {
-		t.Fatalf("the step function %s accepts %d arguments but %d received",
-			d.String(),
-			d.Type().NumIn(),
-			len(params)+contextArgumentsNumber)
 
-		return
+	// the step function left running after the timeout mustn't share values with following hooks and steps
+	callCtx := ctx
+	if timeout > 0 {
+		callCtx = ctx.Clone()
 	}
 
-	in := []reflect.Value{reflect.ValueOf(t), reflect.ValueOf(ctx)}
+	stepCtx, cancel := newStepContext(callCtx, t, timeout)
+	defer cancel()
 
-	for i, v := range params {
-		if len(params) < i+1 {
-			break
+	var in []reflect.Value
+
+	if d.Type().In(0) == stdContextType {
+		in = []reflect.Value{reflect.ValueOf(&stepCtx).Elem()}
+	} else {
+		tv := reflect.ValueOf(t)
+		if r, ok := t.(*stepRecorder); ok && !tv.Type().AssignableTo(d.Type().In(0)) {
+			// the step function expects the concrete type (like *testing.T) instead of StepTest
+			tv = reflect.ValueOf(r.TestingT)
 		}
 
-		inType := d.Type().In(i + contextArgumentsNumber)
+		cv := reflect.ValueOf(callCtx)
+		if d.Type().In(1) == stdContextType {
+			cv = reflect.ValueOf(&stepCtx).Elem()
+		}
 
-		paramType, err := paramType(v, inType)
-		if err != nil {
-			t.Fatal(err)
+		in = []reflect.Value{tv, cv}
+	}
+
+	in = append(in, arguments...)
+
+	if timeout <= 0 {
+		def.call(ctx, t, in)
+
+		return nil
+	}
+
+	if err := callWithTimeout(timeout, func() { def.call(callCtx, t, in) }); err != nil {
+		return err
+	}
+
+	for key, value := range callCtx.values {
+		ctx.Set(key, value)
+	}
+
+	return nil
+}
+
+// call calls the step function with given arguments and handles its results, panics are reported as errors
+func (def *stepDef) call(ctx Context, t TestingT, in []reflect.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("%+v", r)
 		}
+	}()
 
-		in = append(in, paramType)
+	handleResults(ctx, t, reflect.ValueOf(def.f).Call(in))
+}
+
+var (
+	dataTableType       = reflect.TypeOf(msgs.DataTable{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// checkParamType checks whether values can be converted to the type of the step function's argument.
+// Besides types supported by checkTextType, messages.DataTable and types data tables can be bound to are supported.
+func checkParamType(inType reflect.Type) error {
+	err := checkTextType(inType)
+	if err == nil || inType == dataTableType {
+		return nil
 	}
 
-	d.Call(in)
+	switch inType.Kind() { // nolint:exhaustive // other kinds can't be bound to data tables
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		return checkDataTableType(inType)
+	default:
+		return err
+	}
 }
 
-func paramType(param interface{}, inType reflect.Type) (reflect.Value, error) {
+// checkTextType checks whether the text can be converted to the type.
+// Supported types are strings, bools, all sizes of integers and floats, time.Duration, []byte,
+// types implementing encoding.TextUnmarshaler or json.Unmarshaler, types based on them and pointers to them.
+func checkTextType(inType reflect.Type) error {
+	if reflect.PtrTo(inType).Implements(textUnmarshalerType) || reflect.PtrTo(inType).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
 	switch inType.Kind() { // nolint:exhaustive // the linter does not recognize 'default:' to satisfy exhaustiveness
-	case reflect.String:
-		s, err := shouldBeString(param)
-		return reflect.ValueOf(s), err
-	case reflect.Int:
-		v, err := shouldBeInt(param)
-		return reflect.ValueOf(v), err
-	case reflect.Float32:
-		v, err := shouldBeFloat(param, 32) // nolint:mnd
-		return reflect.ValueOf(float32(v)), err
-	case reflect.Float64:
-		v, err := shouldBeFloat(param, 64) // nolint:mnd
-		return reflect.ValueOf(v), err
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Ptr:
+		return checkTextType(inType.Elem())
 	case reflect.Slice:
 		// only []byte is supported
-		if inType != reflect.TypeOf([]byte(nil)) {
-			return reflect.Value{}, fmt.Errorf("the slice argument type %s is not supported", inType.Kind())
+		if inType.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("the slice argument type %s is not supported", inType)
 		}
 
-		v, err := shouldBeByteSlice(param)
+		return nil
+	default:
+		return fmt.Errorf("the type %s is not supported", inType)
+	}
+}
 
-		return reflect.ValueOf(v), err
-	case reflect.Struct:
-		// the only struct supported is the one introduced by cucumber
-		if inType != reflect.TypeOf(msgs.DataTable{}) {
-			return reflect.Value{}, fmt.Errorf("the struct argument type %s is not supported", inType.Kind())
-		}
+func paramType(param interface{}, inType reflect.Type) (reflect.Value, error) {
+	if err := checkParamType(inType); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if table, ok := param.(msgs.DataTable); ok && inType != dataTableType {
+		return bindDataTable(table, inType)
+	}
 
+	switch {
+	case inType == dataTableType:
 		v, err := shouldBeDataTable(param)
 
 		return reflect.ValueOf(v), err
-	default:
-		return reflect.Value{}, fmt.Errorf("the type %s is not supported", inType.Kind())
+	case inType.Kind() == reflect.Slice && !reflect.PtrTo(inType).Implements(textUnmarshalerType):
+		v, err := shouldBeByteSlice(param)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return reflect.ValueOf(v).Convert(inType), nil
 	}
+
+	s, err := shouldBeString(param)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return convertText(s, inType)
 }
 
-func shouldBeDataTable(input interface{}) (msgs.DataTable, error) {
-	if v, ok := input.(msgs.DataTable); ok {
-		return v, nil
+// convertText converts the text to a value of the given type, the type has to be supported by checkTextType
+func convertText(s string, inType reflect.Type) (reflect.Value, error) {
+	v, err := textParamType(s, inType)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot convert %q to %s: %w", s, inType, err)
 	}
 
-	return msgs.DataTable{}, fmt.Errorf("cannot convert %v of type %T to messages.DataTable", input, input)
+	return v, nil
 }
 
-func shouldBeByteSlice(input interface{}) ([]byte, error) {
-	if v, ok := input.([]byte); ok {
-		return v, nil
+// textParamType converts the text to a value of the given type, the type has to be supported by checkTextType
+func textParamType(s string, inType reflect.Type) (reflect.Value, error) {
+	if inType.Kind() == reflect.Ptr {
+		v, err := textParamType(s, inType.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(inType.Elem())
+		ptr.Elem().Set(v)
+
+		return ptr, nil
 	}
 
-	return nil, fmt.Errorf("cannot convert %v of type %T to []byte", input, input)
+	ptr := reflect.New(inType)
+
+	switch u := ptr.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return ptr.Elem(), u.UnmarshalText([]byte(s))
+	case json.Unmarshaler:
+		return ptr.Elem(), u.UnmarshalJSON([]byte(s))
+	}
+
+	v := ptr.Elem()
+
+	switch inType.Kind() { // nolint:exhaustive // other kinds are rejected by checkParamType
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if inType == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			v.SetInt(int64(d))
+
+			break
+		}
+
+		i, err := strconv.ParseInt(s, 10, inType.Bits()) // nolint:mnd
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, inType.Bits()) // nolint:mnd
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, inType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetFloat(f)
+	}
+
+	return v, nil
 }
 
-func shouldBeInt(input interface{}) (int, error) {
-	s, err := shouldBeString(input)
-	if err != nil {
-		return 0, err
+func shouldBeDataTable(input interface{}) (msgs.DataTable, error) {
+	if v, ok := input.(msgs.DataTable); ok {
+		return v, nil
 	}
 
-	return strconv.Atoi(s)
+	return msgs.DataTable{}, fmt.Errorf("cannot convert %v of type %T to messages.DataTable", input, input)
 }
 
-func shouldBeFloat(input interface{}, bitSize int) (float64, error) {
-	s, err := shouldBeString(input)
-	if err != nil {
-		return 0, err
+func shouldBeByteSlice(input interface{}) ([]byte, error) {
+	switch v := input.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
 	}
 
-	return strconv.ParseFloat(s, bitSize)
+	return nil, fmt.Errorf("cannot convert %v of type %T to []byte", input, input)
 }
 
 func shouldBeString(input interface{}) (string, error) {
@@ -710,13 +1972,41 @@ func shouldBeString(input interface{}) (string, error) {
 	}
 }
 
-func (s *Suite) findStepDef(text string) (stepDef, error) {
+// findStepDef finds the step definition matching the text. Step definitions matching the whole text are preferred
+// to ones matching only a part of it and only step definitions with the highest priority are taken into account.
+// When more than one of them matches the whole text, the step is ambiguous and all of them are returned
+// as candidates. The one with the most matches is chosen.
+func (s *Suite) findStepDef(text string) (stepDef, []stepDef, error) {
 	var sd stepDef
 
+	candidates := s.matchingStepDefs(text)
+	if len(candidates) == 0 {
+		return sd, nil, errors.New("cannot find step definition")
+	}
+
+	full := []stepDef{}
+
+	for _, def := range candidates {
+		if matchesWholeText(def, text) {
+			full = append(full, def)
+		}
+	}
+
+	if len(full) > 0 {
+		candidates = full
+	}
+
+	priority := candidates[0].priority
+	for _, def := range candidates {
+		if def.priority > priority {
+			priority = def.priority
+		}
+	}
+
 	found := 0
 
 	for _, step := range s.steps {
-		if !step.expr.MatchString(text) {
+		if step.priority != priority || !step.expr.MatchString(text) || len(full) > 0 && !matchesWholeText(step, text) {
 			continue
 		}
 
@@ -726,15 +2016,98 @@ func (s *Suite) findStepDef(text string) (stepDef, error) {
 		}
 	}
 
-	if reflect.DeepEqual(sd, stepDef{}) {
-		return sd, errors.New("cannot find step definition")
+	ambiguous := []stepDef{}
+
+	for _, def := range full {
+		if def.priority == priority {
+			ambiguous = append(ambiguous, def)
+		}
+	}
+
+	if len(ambiguous) < 2 { // nolint:mnd
+		return sd, nil, nil
 	}
 
-	return sd, nil
+	return sd, ambiguous, nil
+}
+
+// matchingStepDefs returns all step definitions matching the text. Step definitions calling the same step function,
+// like expressions created from one step definition with different parameter types, are considered as one.
+func (s *Suite) matchingStepDefs(text string) []stepDef {
+	defs := []stepDef{}
+
+	for _, def := range s.steps {
+		if !def.expr.MatchString(text) {
+			continue
+		}
+
+		defs = appendStepDef(defs, def)
+	}
+
+	return defs
+}
+
+// matchesWholeText tells whether the match of the step definition covers the whole text
+func matchesWholeText(def stepDef, text string) bool {
+	loc := def.expr.FindStringIndex(text)
+
+	return loc != nil && loc[0] == 0 && loc[1] == len(text)
+}
+
+// appendStepDef appends the step definition unless another step definition calling the same step function
+// is already there, like another expression of the same step definition
+func appendStepDef(defs []stepDef, def stepDef) []stepDef {
+	for _, found := range defs {
+		if sameStepFunc(found, def) {
+			return defs
+		}
+	}
+
+	return append(defs, def)
+}
+
+// closureFuncName matches names of anonymous functions and method values, like pkg.TestX.func1 or pkg.(*T).M-fm
+var closureFuncName = regexp.MustCompile(`\.func\d+(\.\d+)*$|-fm$`)
+
+// sameStepFunc tells whether both step definitions call the same step function. Closures and method values
+// sharing the code can capture different values, so they're the same only when registered at the same place,
+// for example by a helper registering steps called many times.
+func sameStepFunc(a, b stepDef) bool {
+	pc := reflect.ValueOf(a.f).Pointer()
+	if pc != reflect.ValueOf(b.f).Pointer() {
+		return false
+	}
+
+	if f := runtime.FuncForPC(pc); f != nil && closureFuncName.MatchString(f.Name()) {
+		return a.file == b.file && a.line == b.line
+	}
+
+	return true
+}
+
+// partiallyMatchingStepDefs returns step definitions which would match the text
+// if the full-text matching was disabled
+func (s *Suite) partiallyMatchingStepDefs(text string) []stepDef {
+	defs := []stepDef{}
+
+	for _, def := range s.steps {
+		if def.unanchored == nil || !def.unanchored.MatchString(text) {
+			continue
+		}
+
+		defs = appendStepDef(defs, def)
+	}
+
+	return defs
 }
 
 func (s *Suite) shouldSkipFeatureOrRule(featureOrRuleTags []*msgs.Tag) bool {
-	for _, tag := range featureOrRuleTags {
+	return s.isIgnored(featureOrRuleTags)
+}
+
+// isIgnored tells whether any of tags is configured as ignored
+func (s *Suite) isIgnored(tags []*msgs.Tag) bool {
+	for _, tag := range tags {
 		if contains(s.options.ignoreTags, tag.Name) {
 			return true
 		}
@@ -744,10 +2117,12 @@ func (s *Suite) shouldSkipFeatureOrRule(featureOrRuleTags []*msgs.Tag) bool {
 }
 
 func (s *Suite) shouldSkipScenario(scenarioTags []*msgs.Tag) bool {
-	for _, tag := range scenarioTags {
-		if contains(s.options.ignoreTags, tag.Name) {
-			return true
-		}
+	if s.isIgnored(scenarioTags) {
+		return true
+	}
+
+	if !s.tagExpression.evaluate(tagNames(scenarioTags)) {
+		return true
 	}
 
 	if len(s.options.tags) == 0 {
@@ -763,15 +2138,6 @@ func (s *Suite) shouldSkipScenario(scenarioTags []*msgs.Tag) bool {
 	return true
 }
 
-func (s *Suite) getBackgroundSteps(backgrounds []*msgs.Background) []*msgs.Step {
-	result := []*msgs.Step{}
-	for _, background := range backgrounds {
-		result = append(result, background.Steps...)
-	}
-
-	return result
-}
-
 // contains tells whether a contains x.
 func contains(a []string, x string) bool {
 	for _, n := range a {
@@ -782,17 +2148,3 @@ func contains(a []string, x string) bool {
 
 	return false
 }
-
-func getRegexpForVar(v interface{}) string {
-	s := v.(string)
-
-	if _, err := strconv.Atoi(s); err == nil {
-		return "(\\d+)"
-	}
-
-	if _, err := strconv.ParseFloat(s, 32); err == nil {
-		return "([+-]?([0-9]*[.])?[0-9]+)"
-	}
-
-	return "(.*)"
-}
@@ -59,3 +59,7 @@ func (f fsFeature) Open() (io.Reader, error) {
 
 	return file, nil
 }
+
+func (f fsFeature) URI() string {
+	return f.file
+}
@@ -1,11 +1,15 @@
 package gobdd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	msgs "github.com/cucumber/messages/go/v28"
 	"github.com/go-bdd/assert"
@@ -77,6 +81,17 @@ func TestParameterTypes(t *testing.T) {
 	suite.Run()
 }
 
+func TestParameterTypes_WithoutGroups(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/example.feature"))
+	suite.AddParameterTypes(`{number}`, []string{`\d+`})
+	suite.AddStep(`I add ({number}) and ({number})`, add)
+	suite.AddStep(`the result should equal {number}`, func(t StepTest, ctx Context) {
+		check(t, ctx, 3)
+	})
+
+	suite.Run()
+}
+
 func TestArguments(t *testing.T) {
 	suite := NewSuite(t, WithFeaturesPath("features/argument.feature"))
 	suite.AddStep(`the result should equal argument:`, checkt)
@@ -110,8 +125,7 @@ func TestScenarioOutlineExecutesAllTests(t *testing.T) {
 }
 
 func TestStepFromExample(t *testing.T) {
-	s := NewSuite(t)
-	st, expr := s.stepFromExample("I add <d1> and <d2>", &msgs.TableRow{
+	st := stepFromExample("I add <d1> and <d2>", &msgs.TableRow{
 		Cells: []*msgs.TableCell{
 			{Value: "1"},
 			{Value: "2"},
@@ -125,10 +139,6 @@ func TestStepFromExample(t *testing.T) {
 	if err := assert.Equals("I add 1 and 2", st); err != nil {
 		t.Error(err)
 	}
-
-	if err := assert.Equals(`I add (\d+) and (\d+)`, expr); err != nil {
-		t.Error(err)
-	}
 }
 
 func TestBackground(t *testing.T) {
@@ -244,6 +254,151 @@ func TestIgnoredTags(t *testing.T) {
 	suite.Run()
 }
 
+func TestAmbiguousSteps(t *testing.T) {
+	suite := NewSuite(t)
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I add {int} and {int}`, addf)
+	suite.AddStep(`the result should equal {text}`, checkt)
+
+	def, ambiguous, err := suite.findStepDef("I add 1 and 2")
+	require.NoError(t, err)
+	require.NotNil(t, def.expr)
+	require.Len(t, ambiguous, 2)
+	require.Equal(t, `I add (\d+) and (\d+)`, ambiguous[0].source)
+	require.Equal(t, `I add {int} and {int}`, ambiguous[1].source)
+
+	_, ambiguous, err = suite.findStepDef(`the result should equal "3"`)
+	require.NoError(t, err)
+	require.Empty(t, ambiguous, "expressions created from one step definition are not ambiguous")
+
+	err = errAmbiguousStep(&testStep{step: &msgs.Step{Keyword: "When ", Text: "I add 1 and 2"}}, ambiguous)
+	require.Contains(t, err.Error(), "the step When I add 1 and 2 matches many step definitions:")
+}
+
+func TestAmbiguousSteps_PartialMatch(t *testing.T) {
+	suite := NewSuite(t, WithFailOnAmbiguousSteps())
+	suite.AddStep(`I add (\d+)`, func(StepTest, Context, int) {})
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+
+	def, ambiguous, err := suite.findStepDef("I add 1 and 2")
+	require.NoError(t, err)
+	require.Empty(t, ambiguous, "the step definition matching a part of the text is not ambiguous")
+	require.Equal(t, `I add (\d+) and (\d+)`, def.source)
+
+	def, ambiguous, err = suite.findStepDef("I add 1 and more")
+	require.NoError(t, err)
+	require.Empty(t, ambiguous)
+	require.Equal(t, `I add (\d+)`, def.source, "steps matching a part of the text are still executed")
+}
+
+func TestAmbiguousSteps_SameStepFunction(t *testing.T) {
+	suite := NewSuite(t)
+	register := func(expr string) {
+		suite.AddStep(expr, func(StepTest, Context, int) {})
+	}
+
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I add {int} and {int}`, add)
+	register(`the result is (\d+)`)
+	register(`the result is {int}`)
+	suite.AddStep(`the result is (\d+)`, func(StepTest, Context, int) {})
+
+	_, ambiguous, err := suite.findStepDef("I add 1 and 2")
+	require.NoError(t, err)
+	require.Empty(t, ambiguous, "the same step function registered twice is not ambiguous")
+
+	_, ambiguous, err = suite.findStepDef("the result is 3")
+	require.NoError(t, err)
+	require.Len(t, ambiguous, 2, "closures registered at different places are different step functions")
+	require.NotEqual(t, ambiguous[0].line, ambiguous[1].line)
+}
+
+func TestAmbiguousSteps_LoggedOnce(t *testing.T) {
+	tester := &logRecorder{T: t}
+	suite := NewSuite(tester, WithFeaturesPath("features/background.feature"))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I add {int} and {int}`, addf)
+	suite.AddStep(`the result should equal (\d+)`, check)
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+
+	suite.Run()
+
+	warnings := 0
+
+	for _, message := range tester.logs {
+		if strings.Contains(message, "matches many step definitions") {
+			warnings++
+		}
+	}
+
+	require.Equal(t, 1, warnings, "the background step is executed twice, but the warning is logged once")
+}
+
+// logRecorder records messages logged by the suite
+type logRecorder struct {
+	*testing.T
+	logs []string
+}
+
+func (r *logRecorder) Logf(format string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+	r.T.Logf(format, args...)
+}
+
+func TestWithStepPriority(t *testing.T) {
+	overridden := false
+	suite := NewSuite(t, WithFeaturesPath("features/example.feature"), WithFailOnAmbiguousSteps())
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I add {int} and {int}`, func(t StepTest, ctx Context, var1, var2 int) {
+		overridden = true
+		add(t, ctx, var1, var2)
+	}, WithStepPriority(1))
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	def, ambiguous, err := suite.findStepDef("I add 1 and 2")
+	require.NoError(t, err)
+	require.Empty(t, ambiguous)
+	require.Equal(t, 1, def.priority)
+
+	suite.Run()
+
+	require.True(t, overridden)
+}
+
+func TestWithFullTextStepMatching(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/parameter-types.feature"), WithFullTextStepMatching())
+	suite.AddStep(`I add {int} and {int}`, add)
+	suite.AddStep(`the result should equal {int}`, check)
+	suite.AddStep(`I add floats {float} and {float}`, addf)
+	suite.AddStep(`the result should equal float {float}`, checkf)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+	suite.AddStep(`I use word {word}`, func(StepTest, Context, string) {})
+	suite.AddStep(`I use text {text}`, func(_ StepTest, ctx Context, text string) {
+		ctx.Set("stringRes", text)
+	})
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddRegexStep(regexp.MustCompile(`I format text "(.*)" with int (-?\d+)`),
+		func(_ StepTest, ctx Context, format string, value int) {
+			ctx.Set("stringRes", fmt.Sprintf(format, value))
+		})
+
+	suite.Run()
+
+	_, _, err := suite.findStepDef("I add 1 and 2 and 3")
+	require.Error(t, err)
+
+	step := &testStep{
+		step:    &msgs.Step{Keyword: "When ", Text: "I add 1 and 2 and 3"},
+		partial: suite.partiallyMatchingStepDefs("I add 1 and 2 and 3"),
+	}
+	require.Len(t, step.partial, 1)
+	require.Contains(t, errUndefinedStep(step).Error(),
+		"the step definition I add {int} and {int} (")
+	require.Contains(t, errUndefinedStep(step).Error(),
+		") matches only a part of the step's text, but it has to match the whole text")
+}
+
 func TestInvalidFunctionSignature(t *testing.T) {
 	testCases := map[string]struct {
 		f interface{}
@@ -268,6 +423,122 @@ func TestInvalidFunctionSignature(t *testing.T) {
 	}
 }
 
+type myString string
+
+type point struct {
+	X, Y int
+}
+
+func (p *point) UnmarshalJSON(data []byte) error {
+	var coordinates []int
+	if err := json.Unmarshal(data, &coordinates); err != nil {
+		return err
+	}
+
+	if len(coordinates) != 2 {
+		return errors.New("a point has 2 coordinates")
+	}
+
+	p.X, p.Y = coordinates[0], coordinates[1]
+
+	return nil
+}
+
+func TestParamType(t *testing.T) {
+	one := 1
+	testCases := map[string]struct {
+		param    interface{}
+		expected interface{}
+	}{
+		"int8":             {param: []byte("-12"), expected: int8(-12)},
+		"int16":            {param: []byte("-12"), expected: int16(-12)},
+		"int32":            {param: []byte("-12"), expected: int32(-12)},
+		"int64":            {param: []byte("-9223372036854775808"), expected: int64(-9223372036854775808)},
+		"uint":             {param: []byte("12"), expected: uint(12)},
+		"uint8":            {param: []byte("255"), expected: uint8(255)},
+		"uint16":           {param: []byte("12"), expected: uint16(12)},
+		"uint32":           {param: []byte("12"), expected: uint32(12)},
+		"uint64":           {param: []byte("18446744073709551615"), expected: uint64(18446744073709551615)},
+		"bool":             {param: []byte("true"), expected: true},
+		"duration":         {param: []byte("1m30s"), expected: 90 * time.Second},
+		"named string":     {param: []byte("text"), expected: myString("text")},
+		"pointer":          {param: []byte("1"), expected: &one},
+		"text unmarshaler": {param: "2024-03-15T10:00:00Z", expected: time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)},
+		"pointer to text unmarshaler": {param: []byte("12345678901234567890"),
+			expected: new(big.Int).SetUint64(12345678901234567890)},
+		"json unmarshaler":   {param: "[1, 2]", expected: point{X: 1, Y: 2}},
+		"pointer to json":    {param: "[1, 2]", expected: &point{X: 1, Y: 2}},
+		"named byte slice":   {param: []byte("{}"), expected: json.RawMessage("{}")},
+		"doc string to text": {param: "doc string", expected: "doc string"},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			v, err := paramType(testCase.param, reflect.TypeOf(testCase.expected))
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, v.Interface())
+		})
+	}
+}
+
+func TestParamType_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		param    interface{}
+		inType   reflect.Type
+		expected string
+	}{
+		"out of range": {
+			param: []byte("256"), inType: reflect.TypeOf(uint8(0)),
+			expected: `cannot convert "256" to uint8: strconv.ParseUint: parsing "256": value out of range`,
+		},
+		"negative unsigned": {
+			param: []byte("-1"), inType: reflect.TypeOf(uint(0)),
+			expected: `cannot convert "-1" to uint: strconv.ParseUint: parsing "-1": invalid syntax`,
+		},
+		"bool": {
+			param: []byte("yes"), inType: reflect.TypeOf(false),
+			expected: `cannot convert "yes" to bool: strconv.ParseBool: parsing "yes": invalid syntax`,
+		},
+		"duration": {
+			param: []byte("1 minute"), inType: reflect.TypeOf(time.Second),
+			expected: `cannot convert "1 minute" to time.Duration: time: unknown unit " minute" in duration "1 minute"`,
+		},
+		"json unmarshaler": {
+			param: "[1]", inType: reflect.TypeOf(point{}),
+			expected: `cannot convert "[1]" to gobdd.point: a point has 2 coordinates`,
+		},
+		"unsupported type": {
+			param: []byte("1"), inType: reflect.TypeOf(make(chan int)),
+			expected: "the type chan int is not supported",
+		},
+		"data table to int": {
+			param: msgs.DataTable{}, inType: reflect.TypeOf(0),
+			expected: "the data table cannot be bound to int: the type int is not supported",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			_, err := paramType(testCase.param, testCase.inType)
+			require.EqualError(t, err, testCase.expected)
+		})
+	}
+}
+
+func TestAddStep_UnsupportedArgument(t *testing.T) {
+	tester := &mockTester{}
+	suite := NewSuite(tester)
+	suite.AddStep(`I add {int} and {int}`, func(StepTest, Context, int, chan int) {})
+
+	require.True(t, suite.hasStepErrors)
+	require.Equal(t, []string{"the step function for step `I add {int} and {int}` is incorrect: " +
+		"the argument 4 cannot be converted: the type chan int is not supported"}, tester.errors)
+}
+
 func TestFailureOutput(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -284,7 +555,7 @@ func TestFailureOutput(t *testing.T) {
 			def := stepDef{f: testCase.f}
 
 			tester := &mockTester{}
-			def.run(NewContext(), tester, nil)
+			def.run(NewContext(), tester, nil, 0)
 			err := assert.Equals(testCase.expectedErrors, tester.errors)
 			if err != nil {
 				t.Fatal(err)
@@ -293,6 +564,61 @@ func TestFailureOutput(t *testing.T) {
 	}
 }
 
+func TestSkipStepsAfterFailure(t *testing.T) {
+	formatter := &recordingFormatter{}
+	attempts, executed, afterSteps, afterScenarios := 0, 0, 0, 0
+
+	suite := NewSuite(t, WithFeaturesPath("features/skip-after-failure.feature"), WithFormatters(formatter),
+		WithRetries(1),
+		WithAfterStep(func(Context) {
+			afterSteps++
+		}),
+		WithAfterScenario(func(Context) {
+			afterScenarios++
+		}))
+	suite.AddStep(`the step fails at the first attempt`, func(t StepTest, ctx Context) {
+		attempts++
+		if attempts == 1 {
+			t.Error("the step failed")
+		}
+	})
+	suite.AddStep(`the step is skipped`, func(t StepTest, ctx Context) {
+		t.Skip("the step is not ready")
+	})
+	suite.AddStep(`the step is executed`, func(t StepTest, ctx Context) {
+		executed++
+	})
+	suite.Run()
+
+	require.Equal(t, 2, attempts)
+	require.Equal(t, 2, executed)
+	require.Equal(t, 5, afterSteps)
+	require.Equal(t, 3, afterScenarios)
+	require.Equal(t, []string{
+		"step finished: the step fails at the first attempt (FAILED)",
+		"step finished: the step is executed (SKIPPED)",
+		"step finished: the step is executed (SKIPPED)",
+		"step finished: the step fails at the first attempt (PASSED)",
+		"step finished: the step is executed (PASSED)",
+		"step finished: the step is executed (PASSED)",
+		"step finished: the step is skipped (SKIPPED)",
+		"step finished: the step is executed (SKIPPED)",
+	}, stepFinishedEvents(formatter.events))
+}
+
+// stepFinishedEvents returns only events of finished steps
+func stepFinishedEvents(events []string) []string {
+	finished := []string{}
+
+	for _, event := range events {
+		if strings.HasPrefix(event, "step finished: ") {
+			finished = append(finished, event)
+		}
+	}
+
+	return finished
+}
+
 func addf(_ StepTest, ctx Context, var1, var2 float32) {
 	res := var1 + var2
 	ctx.Set("sumRes", res)
@@ -375,8 +701,10 @@ func pass(_ StepTest, _ Context) {}
 
 type mockTester struct {
 	testing.T
-	fatalCalled int
-	errors      []string
+	fatalCalled   int
+	fatalMessages []string
+	errors        []string
+	skipMessages  []string
 }
 
 var _ TestingT = (*mockTester)(nil)
@@ -385,11 +713,15 @@ func (m *mockTester) Log(...interface{}) {}
 
 func (m *mockTester) Logf(string, ...interface{}) {}
 
-func (m *mockTester) Fatal(...interface{}) {
+func (m *mockTester) Fatal(a ...interface{}) {
 	m.fatalCalled++
+	m.fatalMessages = append(m.fatalMessages, fmt.Sprint(a...))
 }
 
-func (m *mockTester) Fatalf(string, ...interface{}) {}
+func (m *mockTester) Fatalf(format string, a ...interface{}) {
+	m.fatalCalled++
+	m.fatalMessages = append(m.fatalMessages, fmt.Sprintf(format, a...))
+}
 
 func (m *mockTester) Error(a ...interface{}) {
 	m.errors = append(m.errors, fmt.Sprintf("%s", a...))
@@ -399,6 +731,10 @@ func (m *mockTester) Errorf(format string, a ...interface{}) {
 	m.errors = append(m.errors, fmt.Sprintf(format, a...))
 }
 
+func (m *mockTester) Skip(a ...interface{}) {
+	m.skipMessages = append(m.skipMessages, fmt.Sprint(a...))
+}
+
 func (m *mockTester) Parallel() {}
 
 func (m *mockTester) Fail() {}
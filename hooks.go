@@ -0,0 +1,332 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// HookOptions holds all the information about when and in which order the hook should be executed
+type HookOptions struct {
+	tags     string
+	priority int
+}
+
+// WithHookTags makes the hook run only for scenarios matching the tag expression, like `@db and not @readonly`.
+// Step hooks are matched against tags of the scenario the step belongs to. Tags are inherited from features,
+// rules and Examples blocks. Feature and rule hooks are matched against tags of the feature or the rule.
+func WithHookTags(expr string) func(*HookOptions) {
+	return func(options *HookOptions) {
+		options.tags = expr
+	}
+}
+
+// WithHookPriority configures the order of hooks. Before hooks with a higher priority are executed first
+// and after hooks with a higher priority are executed last, so they wrap hooks with lower priorities.
+// Hooks with the same priority are executed in the order of registration. The default priority is 0.
+func WithHookPriority(priority int) func(*HookOptions) {
+	return func(options *HookOptions) {
+		options.priority = priority
+	}
+}
+
+// hookKind describes when the hook is executed, it's used in error messages
+type hookKind string
+
+const (
+	beforeSuiteHook    hookKind = "before suite"
+	afterSuiteHook     hookKind = "after suite"
+	beforeFeatureHook  hookKind = "before feature"
+	afterFeatureHook   hookKind = "after feature"
+	beforeRuleHook     hookKind = "before rule"
+	afterRuleHook      hookKind = "after rule"
+	beforeScenarioHook hookKind = "before scenario"
+	afterScenarioHook  hookKind = "after scenario"
+	beforeStepHook     hookKind = "before step"
+	afterStepHook      hookKind = "after step"
+)
+
+// HookResult describes the outcome of the scenario or the step, it's passed to after hooks
+type HookResult struct {
+	Status msgs.TestStepResultStatus
+	// Err is the error of the first step which didn't pass
+	Err error
+	// FailedStep is the first step which didn't pass, it's nil when all the steps passed
+	FailedStep *msgs.Step
+	Duration   time.Duration
+}
+
+type hook struct {
+	// id and kind are set when the suite is created
+	id      string
+	kind    hookKind
+	f       func(t StepTest, ctx Context, result HookResult)
+	options HookOptions
+	tags    tagExpression
+	// file and line point to the place where the hook has been registered
+	file string
+	line int
+}
+
+// contextHook adapts the hook accepting only the context
+func contextHook(f func(ctx Context)) func(t StepTest, ctx Context, result HookResult) {
+	return func(_ StepTest, ctx Context, _ HookResult) {
+		f(ctx)
+	}
+}
+
+// newHook creates the hook registered by the caller of the function calling newHook
+func newHook(f func(t StepTest, ctx Context, result HookResult), optionClosures []func(*HookOptions)) hook {
+	_, file, line, _ := runtime.Caller(2) // nolint:mnd
+
+	h := hook{f: f, tags: tagTrue{}, file: file, line: line}
+	for _, option := range optionClosures {
+		option(&h.options)
+	}
+
+	return h
+}
+
+// prepareHooks parses tag expressions of hooks and sorts them by priorities. Before hooks with higher priorities
+// come first, after hooks with higher priorities come last.
+func prepareHooks(hooks []hook, before bool) ([]hook, error) {
+	prepared := make([]hook, 0, len(hooks))
+
+	for _, h := range hooks {
+		if h.options.tags != "" {
+			expr, err := parseTagExpression(h.options.tags)
+			if err != nil {
+				return nil, fmt.Errorf("the tag expression of the hook (%s:%d) is incorrect: %w", h.file, h.line, err)
+			}
+
+			h.tags = expr
+		}
+
+		prepared = append(prepared, h)
+	}
+
+	sort.SliceStable(prepared, func(i, j int) bool {
+		if before {
+			return prepared[i].options.priority > prepared[j].options.priority
+		}
+
+		return prepared[i].options.priority < prepared[j].options.priority
+	})
+
+	return prepared, nil
+}
+
+// callHooks calls hooks matching the tags. A panicking or failing hook fails the test with the error pointing
+// to the hook, following hooks are executed anyway. The error of the first failed hook is returned, otherwise
+// the error of the first skipping hook, which wraps ErrSkip.
+func (s *Suite) callHooks(kind hookKind, hooks []hook, ctx Context, t TestingT, allTags []*msgs.Tag,
+	result HookResult) error {
+	errs := hookErrors{}
+	tags := tagNames(allTags)
+
+	for _, h := range hooks {
+		if !h.tags.evaluate(tags) {
+			continue
+		}
+
+		errs.report(t, h.call(kind, t, ctx, result))
+	}
+
+	return errs.err()
+}
+
+// callHookSteps calls scenario or step hooks of the test case like callHooks, but every hook is reported
+// to listeners as a test step of its own, so its failure isn't attributed to the step
+func (s *Suite) callHookSteps(tc *testCase, hooks []*hookStep, ctx Context, t TestingT, result HookResult) error {
+	errs := hookErrors{}
+
+	for _, hs := range hooks {
+		s.notify(func(l listener) { l.hookStarted(tc, hs) })
+
+		start := time.Now()
+		err := hs.hook.call(hs.hook.kind, t, ctx, result)
+
+		hs.finish(err, time.Since(start))
+		s.notify(func(l listener) { l.hookFinished(tc, hs) })
+
+		errs.report(t, err)
+	}
+
+	return errs.err()
+}
+
+// skipHookSteps reports hooks which have not been called, because the test case has been stopped, as skipped
+func (s *Suite) skipHookSteps(tc *testCase, hooks []*hookStep) {
+	for _, hs := range hooks {
+		if hs.finished {
+			continue
+		}
+
+		hs.finish(nil, 0)
+		hs.result.status = msgs.TestStepResultStatus_SKIPPED
+
+		s.notify(func(l listener) { l.hookStarted(tc, hs) })
+		s.notify(func(l listener) { l.hookFinished(tc, hs) })
+	}
+}
+
+// hookErrors collects errors of called hooks
+type hookErrors struct {
+	first error
+	skip  error
+}
+
+// report reports the error of the hook to the test, failures fail the test and skips are logged
+func (e *hookErrors) report(t TestingT, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrSkip):
+		t.Log(err.Error())
+
+		if e.skip == nil {
+			e.skip = err
+		}
+	default:
+		t.Error(err.Error())
+
+		if e.first == nil {
+			e.first = err
+		}
+	}
+}
+
+// err returns the error of the first failed hook, otherwise the error of the first skipping hook
+func (e *hookErrors) err() error {
+	if e.first != nil {
+		return e.first
+	}
+
+	return e.skip
+}
+
+// contextFeature returns the feature stored in the context of feature hooks
+func contextFeature(ctx Context) *msgs.Feature {
+	feature, _ := ctx.values[FeatureKey{}].(*msgs.Feature)
+
+	return feature
+}
+
+// contextRule returns the rule stored in the context of rule hooks
+func contextRule(ctx Context) *msgs.Rule {
+	rule, _ := ctx.values[RuleKey{}].(*msgs.Rule)
+
+	return rule
+}
+
+// call calls the hook and converts its panic, failure or skip to an error
+func (h hook) call(kind hookKind, t StepTest, ctx Context, result HookResult) (err error) {
+	ht := &hookT{StepTest: t}
+
+	defer func() {
+		if r := recover(); r != nil && r != errHookStopped {
+			err = fmt.Errorf("the %s hook (%s:%d) failed: %+v", kind, h.file, h.line, r)
+
+			return
+		}
+
+		switch {
+		case ht.failed:
+			err = fmt.Errorf("the %s hook (%s:%d) failed: %s", kind, h.file, h.line, strings.Join(ht.messages, "\n"))
+		case ht.skipped:
+			err = &hookSkipError{message: fmt.Sprintf("the %s hook (%s:%d) skipped: %s",
+				kind, h.file, h.line, strings.Join(ht.messages, "\n"))}
+		}
+	}()
+
+	h.f(ht, ctx, result)
+
+	return nil
+}
+
+// errHookStopped is used to stop the hook calling FailNow or SkipNow
+var errHookStopped = errors.New("the hook has been stopped")
+
+// hookT is the StepTest passed to hooks. Failures and skips are recorded and reported with the location
+// of the hook, instead of being reported directly to the test.
+type hookT struct {
+	StepTest
+	failed   bool
+	skipped  bool
+	messages []string
+}
+
+func (h *hookT) Error(args ...interface{}) {
+	h.record(fmt.Sprint(args...))
+	h.Fail()
+}
+
+func (h *hookT) Errorf(format string, args ...interface{}) {
+	h.record(fmt.Sprintf(format, args...))
+	h.Fail()
+}
+
+func (h *hookT) Fatal(args ...interface{}) {
+	h.record(fmt.Sprint(args...))
+	h.FailNow()
+}
+
+func (h *hookT) Fatalf(format string, args ...interface{}) {
+	h.record(fmt.Sprintf(format, args...))
+	h.FailNow()
+}
+
+func (h *hookT) Fail() {
+	h.failed = true
+}
+
+func (h *hookT) FailNow() {
+	h.Fail()
+	panic(errHookStopped)
+}
+
+func (h *hookT) Failed() bool {
+	return h.failed
+}
+
+func (h *hookT) Skip(args ...interface{}) {
+	h.record(fmt.Sprint(args...))
+	h.SkipNow()
+}
+
+func (h *hookT) Skipf(format string, args ...interface{}) {
+	h.record(fmt.Sprintf(format, args...))
+	h.SkipNow()
+}
+
+func (h *hookT) SkipNow() {
+	h.skipped = true
+	panic(errHookStopped)
+}
+
+func (h *hookT) Skipped() bool {
+	return h.skipped
+}
+
+func (h *hookT) record(message string) {
+	if message != "" {
+		h.messages = append(h.messages, message)
+	}
+}
+
+// hookSkipError is returned when the hook calls Skip, it wraps ErrSkip
+type hookSkipError struct {
+	message string
+}
+
+func (e *hookSkipError) Error() string {
+	return e.message
+}
+
+func (e *hookSkipError) Unwrap() error {
+	return ErrSkip
+}
@@ -0,0 +1,417 @@
+package gobdd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks_Priorities(t *testing.T) {
+	calls := []string{}
+	hook := func(name string) func(Context) {
+		return func(Context) {
+			calls = append(calls, name)
+		}
+	}
+
+	suite := NewSuite(t, WithFeaturesPath("features/empty.feature"),
+		WithBeforeScenario(hook("before 1")),
+		WithBeforeScenario(hook("before 2")),
+		WithBeforeScenario(hook("before 3"), WithHookPriority(10)),
+		WithAfterScenario(hook("after 1")),
+		WithAfterScenario(hook("after 2"), WithHookPriority(10)),
+		WithAfterScenario(hook("after 3")),
+	)
+	suite.Run()
+
+	require.Equal(t, []string{"before 3", "before 1", "before 2", "after 1", "after 3", "after 2"}, calls)
+}
+
+func TestHooks_Tags(t *testing.T) {
+	scenarioHooks, stepHooks := 0, 0
+
+	suite := NewSuite(t, WithFeaturesPath("features/hooks.feature"),
+		WithBeforeScenario(func(ctx Context) {
+			scenarioHooks++
+			ctx.Set("db", true)
+		}, WithHookTags("@db")),
+		WithAfterStep(func(Context) {
+			stepHooks++
+		}, WithHookTags("@db or @api")),
+		WithBeforeScenario(func(Context) {
+			t.Error("the hook should not be called")
+		}, WithHookTags("not @db and @api")),
+	)
+	suite.AddStep(`the database is connected`, func(t StepTest, ctx Context) {
+		if _, err := ctx.Get("db"); err != nil {
+			t.Error("the hook has not been called")
+		}
+	})
+	suite.AddStep(`the database is not connected`, func(t StepTest, ctx Context) {
+		if _, err := ctx.Get("db"); err == nil {
+			t.Error("the hook has been called")
+		}
+	})
+	suite.Run()
+
+	require.Equal(t, 1, scenarioHooks)
+	require.Equal(t, 1, stepHooks)
+}
+
+func TestHooks_FailedBeforeScenario(t *testing.T) {
+	formatter := &attemptsFormatter{}
+	steps, afterScenarios := 0, 0
+
+	suite := NewSuite(t, WithFeaturesPath("features/hooks.feature"), WithFormatters(formatter), WithRetries(1),
+		WithBeforeScenario(func(Context) {
+			if len(formatter.scenarios) == 0 {
+				panic("cannot connect to the database")
+			}
+		}, WithHookTags("@db")),
+		WithAfterScenario(func(Context) {
+			afterScenarios++
+		}, WithHookTags("@db")),
+	)
+	suite.AddStep(`the database is( not)? connected`, func(StepTest, Context, string) {
+		steps++
+	})
+	suite.Run()
+
+	require.Equal(t, 2, steps)
+	require.Equal(t, 2, afterScenarios)
+	require.Len(t, formatter.scenarios, 3)
+
+	failed := formatter.scenarios[0]
+	require.Equal(t, msgs.TestStepResultStatus_FAILED, failed.Status)
+	require.Regexp(t, `^the before scenario hook \(.*hooks_test.go:\d+\) failed: cannot connect to the database$`,
+		failed.Err.Error())
+	require.True(t, formatter.scenarios[1].Flaky)
+}
+
+func TestHooks_FailedStepHook(t *testing.T) {
+	tester := &mockTester{}
+	recorder := &stepRecorder{TestingT: tester}
+	suite := NewSuite(t)
+	hooks := []hook{
+		newHook(contextHook(func(Context) { panic(fmt.Errorf("the first hook failed")) }), nil),
+		newHook(contextHook(func(Context) { panic("the second hook failed") }), nil),
+	}
+
+	err := suite.callHooks(afterStepHook, hooks, NewContext(), recorder, nil, HookResult{})
+
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "the after step hook ("))
+	require.True(t, strings.HasSuffix(err.Error(), ") failed: the first hook failed"))
+	require.Len(t, tester.errors, 2)
+	require.Contains(t, recorder.err().Error(), err.Error())
+}
+
+func TestHooks_InvalidTagExpression(t *testing.T) {
+	tester := &mockTester{}
+
+	NewSuite(tester, WithAfterStep(func(Context) {}, WithHookTags("@db and")))
+
+	require.Len(t, tester.fatalMessages, 1)
+	require.Contains(t, tester.fatalMessages[0], "hooks_test.go:")
+	require.Contains(t, tester.fatalMessages[0], ") is incorrect: ")
+}
+
+func TestHooks_SuiteFeatureAndRule(t *testing.T) {
+	calls := []string{}
+	hook := func(name string) func(Context) {
+		return func(Context) {
+			calls = append(calls, name)
+		}
+	}
+
+	suite := NewSuite(t, WithFeaturesPath("features/suite-hooks.feature"),
+		WithBeforeSuite(func(ctx Context) {
+			calls = append(calls, "before suite")
+			ctx.Set("server", "started")
+		}),
+		WithAfterSuite(hook("after suite")),
+		WithBeforeFeature(func(ctx Context, feature *msgs.Feature) {
+			calls = append(calls, "before feature "+feature.Name)
+			ctx.Set("rule", "")
+		}, WithHookTags("@server")),
+		WithAfterFeature(func(ctx Context, feature *msgs.Feature) {
+			calls = append(calls, "after feature "+feature.Name)
+		}),
+		WithBeforeRule(func(ctx Context, rule *msgs.Rule) {
+			calls = append(calls, "before rule "+rule.Name)
+			ctx.Set("rule", rule.Name)
+		}, WithHookTags("@server and @users")),
+		WithAfterRule(func(ctx Context, rule *msgs.Rule) {
+			calls = append(calls, "after rule "+rule.Name)
+		}, WithHookTags("@admins")),
+		WithBeforeScenario(hook("before scenario")),
+	)
+	suite.AddStep(`the (server|rule) is "(.*)"`, func(t StepTest, ctx Context, key, expected string) {
+		actual, err := ctx.GetString(key)
+		if err != nil || actual != expected {
+			t.Errorf("expected the %s to be %q but got %q (%v)", key, expected, actual, err)
+		}
+
+		// values set by scenarios are not visible in other scenarios
+		ctx.Set("server", "changed by the scenario")
+	})
+	suite.Run()
+
+	require.Equal(t, []string{
+		"before suite",
+		"before feature suite hooks",
+		"before scenario",
+		"before rule the rule",
+		"before scenario",
+		"after feature suite hooks",
+		"after suite",
+	}, calls)
+}
+
+func TestHooks_FilteredFeatureAndRule(t *testing.T) {
+	calls := []string{}
+
+	suite := NewSuite(t, WithFeaturesPath("features/*hooks.feature"), WithTags("@users"),
+		WithBeforeFeature(func(ctx Context, feature *msgs.Feature) {
+			calls = append(calls, "before feature "+feature.Name)
+		}),
+		WithAfterFeature(func(ctx Context, feature *msgs.Feature) {
+			calls = append(calls, "after feature "+feature.Name)
+		}),
+		WithBeforeRule(func(ctx Context, rule *msgs.Rule) {
+			calls = append(calls, "before rule "+rule.Name)
+		}),
+		WithAfterRule(func(ctx Context, rule *msgs.Rule) {
+			calls = append(calls, "after rule "+rule.Name)
+		}),
+	)
+	suite.AddStep(`the (server|rule) is "(.*)"`, func(StepTest, Context, string, string) {})
+	suite.Run()
+
+	// all the scenarios of the hooks feature are filtered out
+	require.Equal(t, []string{
+		"before feature suite hooks",
+		"before rule the rule",
+		"after rule the rule",
+		"after feature suite hooks",
+	}, calls)
+}
+
+func TestHooks_FailedBeforeSuite(t *testing.T) {
+	formatter := &recordingFormatter{}
+	tester := &mockTester{}
+	afterSuites := 0
+
+	suite := NewSuite(tester, WithFeaturesPath("features/suite-hooks.feature"), WithFormatters(formatter),
+		WithBeforeSuite(func(Context) {
+			panic("cannot start the server")
+		}),
+		WithAfterSuite(func(Context) {
+			afterSuites++
+		}),
+		WithBeforeFeature(func(Context, *msgs.Feature) {
+			t.Error("the hook should not be called")
+		}),
+	)
+	suite.Run()
+
+	require.Equal(t, 1, afterSuites)
+	require.Len(t, tester.errors, 1)
+	require.Contains(t, tester.errors[0], "failed: cannot start the server")
+	require.Contains(t, formatter.events, "scenario finished: the scenario of the feature (SKIPPED)")
+	require.Contains(t, formatter.events, "scenario finished: the scenario of the rule (SKIPPED)")
+	require.False(t, formatter.run.Success)
+}
+
+func TestHooks_Results(t *testing.T) {
+	scenarios, steps := []HookResult{}, []HookResult{}
+	attempts := 0
+
+	suite := NewSuite(t, WithFeaturesPath("features/hooks.feature"), WithTags("@db"), WithRetries(1),
+		WithAfterScenarioT(func(t StepTest, ctx Context, result HookResult) {
+			scenarios = append(scenarios, result)
+		}),
+		WithAfterStepT(func(t StepTest, ctx Context, result HookResult) {
+			steps = append(steps, result)
+		}),
+	)
+	suite.AddStep(`the database is connected`, func(t StepTest, ctx Context) {
+		attempts++
+		if attempts == 1 {
+			t.Error("the database is not available")
+		}
+	})
+	suite.Run()
+
+	require.Len(t, scenarios, 2)
+	require.Equal(t, msgs.TestStepResultStatus_FAILED, scenarios[0].Status)
+	require.EqualError(t, scenarios[0].Err, "the database is not available")
+	require.Equal(t, "the database is connected", scenarios[0].FailedStep.Text)
+	require.Equal(t, HookResult{Status: msgs.TestStepResultStatus_PASSED, Duration: scenarios[1].Duration},
+		scenarios[1])
+
+	require.Len(t, steps, 2)
+	require.Equal(t, msgs.TestStepResultStatus_FAILED, steps[0].Status)
+	require.EqualError(t, steps[0].Err, "the database is not available")
+	require.Equal(t, "the database is connected", steps[0].FailedStep.Text)
+	require.Equal(t, msgs.TestStepResultStatus_PASSED, steps[1].Status)
+	require.Nil(t, steps[1].FailedStep)
+}
+
+func TestHooks_FailedAfterStepReportedSeparately(t *testing.T) {
+	formatter := &recordingFormatter{}
+	buf := bytes.NewBuffer(nil)
+	attempts := 0
+
+	suite := NewSuite(t, WithFeaturesPath("features/hooks.feature"), WithTags("@db"), WithRetries(1),
+		WithFormatters(formatter), WithMessagesOutput(buf),
+		WithBeforeScenario(func(Context) {
+			attempts++
+		}),
+		WithAfterStepT(func(t StepTest, ctx Context, result HookResult) {
+			if attempts == 1 {
+				t.Error("cannot close the connection")
+			}
+		}),
+	)
+	suite.AddStep(`the database is connected`, func(StepTest, Context) {})
+	suite.Run()
+
+	require.Equal(t, []string{
+		"hook finished: before scenario (PASSED)",
+		"step finished: the database is connected (PASSED)",
+		"hook finished: after step (FAILED)",
+		"scenario finished: the scenario with the hook (FAILED)",
+		"hook finished: before scenario (PASSED)",
+		"step finished: the database is connected (PASSED)",
+		"hook finished: after step (PASSED)",
+		"scenario finished: the scenario with the hook (PASSED)",
+	}, finishedEvents(formatter.events))
+	require.True(t, strings.HasSuffix(formatter.hooks[1].Err.Error(), "failed: cannot close the connection"))
+	require.Equal(t, "the database is connected", formatter.hooks[1].Step.Text)
+
+	hookTypes, testStepTypes, failed := map[string]msgs.HookType{}, map[string]msgs.HookType{}, []*msgs.TestStepFinished{}
+
+	for _, envelope := range decodeEnvelopes(t, buf) {
+		switch {
+		case envelope.Hook != nil:
+			hookTypes[envelope.Hook.Id] = envelope.Hook.Type
+		case envelope.TestCase != nil:
+			for _, testStep := range envelope.TestCase.TestSteps {
+				if testStep.HookId != "" {
+					testStepTypes[testStep.Id] = hookTypes[testStep.HookId]
+				}
+			}
+		case envelope.TestStepFinished != nil &&
+			envelope.TestStepFinished.TestStepResult.Status == msgs.TestStepResultStatus_FAILED:
+			failed = append(failed, envelope.TestStepFinished)
+		}
+	}
+
+	require.Len(t, hookTypes, 2)
+	require.Len(t, testStepTypes, 2)
+	require.Len(t, failed, 1)
+	require.Equal(t, msgs.HookType_AFTER_TEST_STEP, testStepTypes[failed[0].TestStepId])
+	require.Equal(t, formatter.hooks[1].Err.Error(), failed[0].TestStepResult.Message)
+}
+
+// finishedEvents returns events about finished steps, hooks and scenarios
+func finishedEvents(events []string) []string {
+	finished := []string{}
+
+	for _, event := range events {
+		if strings.HasPrefix(event, "step finished") || strings.HasPrefix(event, "hook finished") ||
+			strings.HasPrefix(event, "scenario finished") {
+			finished = append(finished, event)
+		}
+	}
+
+	return finished
+}
+
+func TestHooks_Skip(t *testing.T) {
+	formatter := &recordingFormatter{}
+	afterScenarios := 0
+
+	suite := NewSuite(t, WithFeaturesPath("features/hooks.feature"), WithFormatters(formatter),
+		WithBeforeScenarioT(func(t StepTest, ctx Context) {
+			t.Skip("the database is unavailable")
+		}, WithHookTags("@db")),
+		WithBeforeStepT(func(t StepTest, ctx Context) {
+			t.Skipf("the step is not ready")
+		}, WithHookTags("not @db")),
+		WithAfterScenario(func(Context) {
+			afterScenarios++
+		}),
+	)
+	suite.AddStep(`the database is( not)? connected`, func(t StepTest, ctx Context, _ string) {
+		t.Error("the step should not be executed")
+	})
+	suite.Run()
+
+	require.Equal(t, 2, afterScenarios)
+	require.Contains(t, formatter.events, "scenario finished: the scenario with the hook (SKIPPED)")
+	require.Contains(t, formatter.events, "step finished: the database is not connected (SKIPPED)")
+	require.Contains(t, formatter.events, "scenario finished: the scenario without the hook (SKIPPED)")
+}
+
+func TestHook_Call(t *testing.T) {
+	testCases := map[string]struct {
+		f        func(t StepTest)
+		expected string
+		skipped  bool
+	}{
+		"passed": {
+			f: func(t StepTest) { t.Log("the hook passed") },
+		},
+		"error": {
+			f: func(t StepTest) {
+				t.Error("the first error")
+				t.Errorf("the %s error", "second")
+			},
+			expected: "the after step hook (hooks_test.go:1) failed: the first error\nthe second error",
+		},
+		"fatal": {
+			f: func(t StepTest) {
+				t.Fatalf("the %s error", "fatal")
+				t.Error("the hook is not stopped")
+			},
+			expected: "the after step hook (hooks_test.go:1) failed: the fatal error",
+		},
+		"skip": {
+			f: func(t StepTest) {
+				t.Skip("the service is unavailable")
+				t.Error("the hook is not stopped")
+			},
+			expected: "the after step hook (hooks_test.go:1) skipped: the service is unavailable",
+			skipped:  true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			h := hook{
+				f:    func(t StepTest, _ Context, _ HookResult) { testCase.f(t) },
+				file: "hooks_test.go",
+				line: 1,
+			}
+
+			err := h.call(afterStepHook, &mockTester{}, NewContext(), HookResult{})
+			if testCase.expected == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.EqualError(t, err, testCase.expected)
+			require.Equal(t, testCase.skipped, errors.Is(err, ErrSkip))
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package gobdd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// WithJUnitReport configures a writer the JUnit XML report is written to after running the suite.
+// Every feature and every rule is reported as a separate testsuite and every scenario
+// (or every row of a scenario outline) as a testcase.
+func WithJUnitReport(w io.Writer) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.listeners = append(options.listeners, &junitFormatter{
+			w:        w,
+			suites:   map[string]*junitTestSuite{},
+			attempts: map[string][]*junitFailure{},
+		})
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	Timestamp string           `xml:"timestamp,attr,omitempty"`
+	TestCases []*junitTestCase `xml:"testcase"`
+
+	duration time.Duration
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	// FlakyFailures and RerunFailures describe failed attempts of retried test cases which eventually passed
+	// or failed respectively, like in reports of the Maven Surefire plugin
+	FlakyFailures []*junitFailure `xml:"flakyFailure,omitempty"`
+	RerunFailures []*junitFailure `xml:"rerunFailure,omitempty"`
+	SystemOut     string          `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// junitFormatter collects results of test cases and writes the JUnit XML report at the end of the run
+type junitFormatter struct {
+	baseListener
+
+	w      io.Writer
+	report junitTestSuites
+	// suites holds test suites by the feature's URI and the rule's ID
+	suites map[string]*junitTestSuite
+	// attempts holds failures of attempts which have been retried by IDs of test cases
+	attempts map[string][]*junitFailure
+}
+
+func (f *junitFormatter) testCaseFinished(tc *testCase) {
+	if tc.willBeRetried {
+		f.attempts[tc.id] = append(f.attempts[tc.id], junitTestCaseFailure(tc))
+
+		return
+	}
+
+	f.addTestCase(tc)
+}
+
+func (f *junitFormatter) testCaseSkipped(tc *testCase) {
+	f.addTestCase(tc)
+}
+
+func (f *junitFormatter) runFinished(*testRun) error {
+	var total time.Duration
+
+	for _, suite := range f.report.Suites {
+		suite.Time = junitTime(suite.duration)
+		total += suite.duration
+	}
+
+	f.report.Time = junitTime(total)
+
+	data, err := xml.MarshalIndent(f.report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(f.w, xml.Header); err != nil {
+		return err
+	}
+
+	if _, err := f.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *junitFormatter) addTestCase(tc *testCase) {
+	suite := f.suite(tc)
+
+	testCase := &junitTestCase{
+		ClassName: suite.Name,
+		Name:      tc.name(),
+		Time:      junitTime(tc.duration),
+		SystemOut: junitSteps(tc),
+	}
+
+	switch tc.status {
+	case msgs.TestStepResultStatus_PASSED:
+		testCase.FlakyFailures = f.attempts[tc.id]
+	case msgs.TestStepResultStatus_SKIPPED:
+		testCase.Skipped = &junitSkipped{}
+		if tc.err != nil {
+			testCase.Skipped.Message = tc.err.Error()
+		}
+
+		suite.Skipped++
+		f.report.Skipped++
+	default:
+		// pending steps fail the test, so they're reported as failures as well
+		testCase.Failure = junitTestCaseFailure(tc)
+		testCase.RerunFailures = f.attempts[tc.id]
+
+		suite.Failures++
+		f.report.Failures++
+	}
+
+	suite.Tests++
+	suite.duration += tc.duration
+	suite.TestCases = append(suite.TestCases, testCase)
+	f.report.Tests++
+}
+
+// junitTestCaseFailure describes the failed test case
+func junitTestCaseFailure(tc *testCase) *junitFailure {
+	failure := &junitFailure{
+		Type: strings.ToLower(tc.status.String()),
+	}
+
+	err := tc.err
+	if err == nil && tc.status == msgs.TestStepResultStatus_PENDING {
+		err = ErrPending
+	}
+
+	if err != nil {
+		failure.Message = strings.SplitN(err.Error(), "\n", 2)[0] // nolint:mnd
+		failure.Content = err.Error()
+	}
+
+	return failure
+}
+
+// suite returns the test suite of the feature or the rule the test case belongs to
+func (f *junitFormatter) suite(tc *testCase) *junitTestSuite {
+	key := tc.uri
+	name := tc.feature.Name
+
+	if tc.rule != nil {
+		key += "#" + tc.rule.Id
+		name += " / " + tc.rule.Name
+	}
+
+	if suite, ok := f.suites[key]; ok {
+		return suite
+	}
+
+	suite := &junitTestSuite{Name: name}
+
+	if !tc.started.IsZero() {
+		suite.Timestamp = tc.started.Format("2006-01-02T15:04:05")
+	}
+
+	f.suites[key] = suite
+	f.report.Suites = append(f.report.Suites, suite)
+
+	return suite
+}
+
+// junitSteps lists steps of the test case with their statuses and durations
+func junitSteps(tc *testCase) string {
+	if tc.startedID == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for _, step := range tc.steps {
+		fmt.Fprintf(&sb, "%s%s (%s, %ss)\n",
+			step.step.Keyword, step.step.Text, strings.ToLower(step.result.status.String()), junitTime(step.result.duration))
+	}
+
+	return sb.String()
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
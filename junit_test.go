@@ -0,0 +1,137 @@
+package gobdd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithJUnitReport(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/ignored_*tags.feature"), WithIgnoredTags("@ignore"),
+		WithJUnitReport(buf))
+	suite.AddStep(`the test should pass`, pass)
+	suite.AddStep(`fail the test`, fail)
+
+	suite.Run()
+
+	report := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	require.Equal(t, 5, report.Tests)
+	require.Equal(t, 3, report.Skipped)
+	require.Equal(t, 0, report.Failures)
+
+	names := []string{}
+	for _, suite := range report.Suites {
+		names = append(names, suite.Name)
+	}
+
+	require.ElementsMatch(t, []string{
+		"ignored tags",
+		"ignored tags",
+		"ignored tags / this rule should be ignored",
+		"ignored tags / this rule should run",
+	}, names)
+}
+
+func TestWithJUnitReport_Outline(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"), WithJUnitReport(buf))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	report := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	require.Len(t, report.Suites, 1)
+
+	testCases := report.Suites[0].TestCases
+	require.Len(t, testCases, 2)
+	require.Equal(t, "testing outline scenarios (Examples #1.1)", testCases[0].Name)
+	require.Equal(t, "Scenario Outline", testCases[0].ClassName)
+	require.Contains(t, testCases[1].SystemOut, "When I add 5 and 5 (passed, ")
+	require.Contains(t, testCases[1].SystemOut, "Then the result should equal 10 (passed, ")
+}
+
+func TestJUnitFormatter_Failure(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &junitFormatter{w: buf, suites: map[string]*junitTestSuite{}, attempts: map[string][]*junitFailure{}}
+
+	tc := &testCase{
+		uri:       "features/failure.feature",
+		feature:   &msgs.Feature{Name: "failures"},
+		scenario:  &msgs.Scenario{Name: "the scenario fails"},
+		startedID: "1",
+		status:    msgs.TestStepResultStatus_FAILED,
+		err:       errors.New("expected 3 but 4 received\nmore details"),
+		steps: []*testStep{
+			{
+				step:   &msgs.Step{Keyword: "Then ", Text: "the result should equal 3"},
+				result: stepResult{status: msgs.TestStepResultStatus_FAILED},
+			},
+		},
+	}
+
+	f.testCaseFinished(tc)
+	require.NoError(t, f.runFinished(&testRun{}))
+
+	report := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	require.Equal(t, 1, report.Failures)
+
+	failure := report.Suites[0].TestCases[0].Failure
+	require.NotNil(t, failure)
+	require.Equal(t, "expected 3 but 4 received", failure.Message)
+	require.Equal(t, "failed", failure.Type)
+	require.Equal(t, "expected 3 but 4 received\nmore details", failure.Content)
+}
+
+func TestJUnitFormatter_Pending(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected string
+	}{
+		"pending step": {
+			err:      fmt.Errorf("sending emails: %w", ErrPending),
+			expected: "sending emails: the step is pending",
+		},
+		"pending step without error": {expected: "the step is pending"},
+	}
+
+	for name, test := range testCases {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			f := &junitFormatter{w: buf, suites: map[string]*junitTestSuite{}, attempts: map[string][]*junitFailure{}}
+
+			f.testCaseFinished(&testCase{
+				uri:       "features/pending.feature",
+				feature:   &msgs.Feature{Name: "pending"},
+				scenario:  &msgs.Scenario{Name: "the email is sent"},
+				startedID: "1",
+				status:    msgs.TestStepResultStatus_PENDING,
+				err:       test.err,
+			})
+			require.NoError(t, f.runFinished(&testRun{}))
+
+			report := junitTestSuites{}
+			require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+			require.Equal(t, 1, report.Failures)
+			require.Equal(t, 0, report.Skipped)
+
+			failure := report.Suites[0].TestCases[0].Failure
+			require.NotNil(t, failure)
+			require.Nil(t, report.Suites[0].TestCases[0].Skipped)
+			require.Equal(t, "pending", failure.Type)
+			require.Equal(t, test.expected, failure.Message)
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package gobdd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// messagesProtocolVersion is the version of Cucumber Messages produced by the suite
+const messagesProtocolVersion = "28.0.0"
+
+// WithMessagesOutput configures a writer the Cucumber Messages stream (NDJSON) is written to while running the suite.
+// The stream can be consumed by standard Cucumber tools, like the HTML formatter.
+// See https://github.com/cucumber/messages
+func WithMessagesOutput(w io.Writer) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.listeners = append(options.listeners, &messagesFormatter{
+			encoder: json.NewEncoder(w),
+		})
+	}
+}
+
+// messagesFormatter writes every event as a Cucumber Messages envelope
+type messagesFormatter struct {
+	baseListener
+
+	encoder *json.Encoder
+	runID   string
+	// err holds the first error which occurred while writing, next envelopes are not written
+	err error
+}
+
+func (f *messagesFormatter) write(envelope *msgs.Envelope) {
+	if f.err != nil {
+		return
+	}
+
+	f.err = f.encoder.Encode(envelope)
+}
+
+func (f *messagesFormatter) runStarted(run *testRun) {
+	f.runID = run.id
+
+	f.write(&msgs.Envelope{
+		Meta: &msgs.Meta{
+			ProtocolVersion: messagesProtocolVersion,
+			Implementation:  &msgs.Product{Name: "gobdd"},
+			Runtime:         &msgs.Product{Name: "go", Version: runtime.Version()},
+			Os:              &msgs.Product{Name: runtime.GOOS},
+			Cpu:             &msgs.Product{Name: runtime.GOARCH},
+		},
+	})
+
+	// expressions created from one source by applying parameter types are reported as one step definition
+	written := map[string]bool{}
+
+	for i := range run.stepDefs {
+		if written[run.stepDefs[i].id] {
+			continue
+		}
+
+		written[run.stepDefs[i].id] = true
+		f.write(&msgs.Envelope{StepDefinition: stepDefinitionMessage(&run.stepDefs[i])})
+	}
+
+	for i := range run.hooks {
+		f.write(&msgs.Envelope{Hook: hookMessage(&run.hooks[i])})
+	}
+
+	f.write(&msgs.Envelope{
+		TestRunStarted: &msgs.TestRunStarted{
+			Id:        run.id,
+			Timestamp: timestamp(run.started),
+		},
+	})
+}
+
+func (f *messagesFormatter) featureStarted(doc *featureDocument) {
+	f.write(&msgs.Envelope{
+		Source: &msgs.Source{
+			Uri:       doc.uri,
+			Data:      string(doc.source),
+			MediaType: msgs.SourceMediaType_TEXT_X_CUCUMBER_GHERKIN_PLAIN,
+		},
+	})
+	f.write(&msgs.Envelope{GherkinDocument: doc.document})
+}
+
+func (f *messagesFormatter) testCaseStarted(tc *testCase) {
+	if tc.pickle == nil {
+		return
+	}
+
+	// the pickle and the test case are written once, every attempt of the retried test case is started separately
+	if tc.attempt > 0 {
+		f.writeTestCaseStarted(tc)
+
+		return
+	}
+
+	testSteps := make([]*msgs.TestStep, 0, len(tc.steps))
+	testSteps = appendHookTestSteps(testSteps, tc.beforeHooks)
+
+	for _, step := range tc.steps {
+		if step.pickleStep == nil {
+			continue
+		}
+
+		testSteps = appendHookTestSteps(testSteps, step.beforeHooks)
+
+		testStep := &msgs.TestStep{
+			Id:                step.id,
+			PickleStepId:      step.pickleStep.Id,
+			StepDefinitionIds: []string{},
+		}
+
+		if step.def != nil {
+			// all the candidates are listed for ambiguous steps
+			defs := []stepDef{*step.def}
+			if len(step.ambiguous) > 0 {
+				defs = step.ambiguous
+			}
+
+			for i := range defs {
+				testStep.StepDefinitionIds = append(testStep.StepDefinitionIds, defs[i].id)
+				testStep.StepMatchArgumentsLists = append(testStep.StepMatchArgumentsLists,
+					&msgs.StepMatchArgumentsList{StepMatchArguments: stepMatchArguments(&defs[i], step.step.Text)})
+			}
+		}
+
+		testSteps = append(testSteps, testStep)
+		testSteps = appendHookTestSteps(testSteps, step.afterHooks)
+	}
+
+	testSteps = appendHookTestSteps(testSteps, tc.afterHooks)
+
+	// pickles are written only for test cases which are executed, so filtered out scenarios are not reported
+	f.write(&msgs.Envelope{Pickle: tc.pickle})
+	f.write(&msgs.Envelope{
+		TestCase: &msgs.TestCase{
+			Id:               tc.id,
+			PickleId:         tc.pickle.Id,
+			TestSteps:        testSteps,
+			TestRunStartedId: f.runID,
+		},
+	})
+	f.writeTestCaseStarted(tc)
+}
+
+func (f *messagesFormatter) writeTestCaseStarted(tc *testCase) {
+	f.write(&msgs.Envelope{
+		TestCaseStarted: &msgs.TestCaseStarted{
+			Attempt:    int64(tc.attempt),
+			Id:         tc.startedID,
+			TestCaseId: tc.id,
+			Timestamp:  timestamp(tc.started),
+		},
+	})
+}
+
+func (f *messagesFormatter) stepStarted(tc *testCase, step *testStep) {
+	if tc.pickle == nil || step.pickleStep == nil {
+		return
+	}
+
+	f.write(&msgs.Envelope{
+		TestStepStarted: &msgs.TestStepStarted{
+			TestCaseStartedId: tc.startedID,
+			TestStepId:        step.id,
+			Timestamp:         timestamp(time.Now()),
+		},
+	})
+}
+
+func (f *messagesFormatter) stepFinished(tc *testCase, step *testStep, result stepResult) {
+	if tc.pickle == nil || step.pickleStep == nil {
+		return
+	}
+
+	f.writeTestStepFinished(tc, step.id, result)
+}
+
+func (f *messagesFormatter) hookStarted(tc *testCase, hs *hookStep) {
+	if tc.pickle == nil || hs.step != nil && hs.step.pickleStep == nil {
+		return
+	}
+
+	f.write(&msgs.Envelope{
+		TestStepStarted: &msgs.TestStepStarted{
+			TestCaseStartedId: tc.startedID,
+			TestStepId:        hs.id,
+			Timestamp:         timestamp(time.Now()),
+		},
+	})
+}
+
+func (f *messagesFormatter) hookFinished(tc *testCase, hs *hookStep) {
+	if tc.pickle == nil || hs.step != nil && hs.step.pickleStep == nil {
+		return
+	}
+
+	f.writeTestStepFinished(tc, hs.id, hs.result)
+}
+
+func (f *messagesFormatter) writeTestStepFinished(tc *testCase, testStepID string, result stepResult) {
+	stepResult := &msgs.TestStepResult{
+		Duration: duration(result.duration),
+		Status:   result.status,
+	}
+
+	if result.err != nil {
+		stepResult.Message = result.err.Error()
+	}
+
+	f.write(&msgs.Envelope{
+		TestStepFinished: &msgs.TestStepFinished{
+			TestCaseStartedId: tc.startedID,
+			TestStepId:        testStepID,
+			TestStepResult:    stepResult,
+			Timestamp:         timestamp(time.Now()),
+		},
+	})
+}
+
+func (f *messagesFormatter) attached(tc *testCase, step *testStep, a *attachment) {
+	if tc.pickle == nil || step.pickleStep == nil {
+		return
+	}
+
+	body, encoding := string(a.data), msgs.AttachmentContentEncoding_IDENTITY
+	if !strings.HasPrefix(a.mediaType, "text/") {
+		body, encoding = base64.StdEncoding.EncodeToString(a.data), msgs.AttachmentContentEncoding_BASE64
+	}
+
+	f.write(&msgs.Envelope{
+		Attachment: &msgs.Attachment{
+			Body:              body,
+			ContentEncoding:   encoding,
+			MediaType:         a.mediaType,
+			TestCaseStartedId: tc.startedID,
+			TestStepId:        step.id,
+			Timestamp:         timestamp(a.timestamp),
+		},
+	})
+}
+
+func (f *messagesFormatter) testCaseFinished(tc *testCase) {
+	if tc.pickle == nil {
+		return
+	}
+
+	f.write(&msgs.Envelope{
+		TestCaseFinished: &msgs.TestCaseFinished{
+			TestCaseStartedId: tc.startedID,
+			Timestamp:         timestamp(tc.started.Add(tc.duration)),
+			WillBeRetried:     tc.willBeRetried,
+		},
+	})
+}
+
+func (f *messagesFormatter) runFinished(run *testRun) error {
+	f.write(&msgs.Envelope{
+		TestRunFinished: &msgs.TestRunFinished{
+			Success:          run.success,
+			Timestamp:        timestamp(time.Now()),
+			TestRunStartedId: run.id,
+		},
+	})
+
+	return f.err
+}
+
+func stepDefinitionMessage(def *stepDef) *msgs.StepDefinition {
+	patternType := msgs.StepDefinitionPatternType_REGULAR_EXPRESSION
+	if def.cucumberExpression {
+		patternType = msgs.StepDefinitionPatternType_CUCUMBER_EXPRESSION
+	}
+
+	return &msgs.StepDefinition{
+		Id: def.id,
+		Pattern: &msgs.StepDefinitionPattern{
+			Source: def.source,
+			Type:   patternType,
+		},
+		SourceReference: &msgs.SourceReference{
+			Uri:      def.file,
+			Location: &msgs.Location{Line: int64(def.line)},
+		},
+	}
+}
+
+// hookTypes maps kinds of hooks reported as test steps to types of hooks in Cucumber Messages
+var hookTypes = map[hookKind]msgs.HookType{
+	beforeScenarioHook: msgs.HookType_BEFORE_TEST_CASE,
+	afterScenarioHook:  msgs.HookType_AFTER_TEST_CASE,
+	beforeStepHook:     msgs.HookType_BEFORE_TEST_STEP,
+	afterStepHook:      msgs.HookType_AFTER_TEST_STEP,
+}
+
+func hookMessage(h *hook) *msgs.Hook {
+	return &msgs.Hook{
+		Id: h.id,
+		SourceReference: &msgs.SourceReference{
+			Uri:      h.file,
+			Location: &msgs.Location{Line: int64(h.line)},
+		},
+		TagExpression: h.options.tags,
+		Type:          hookTypes[h.kind],
+	}
+}
+
+// appendHookTestSteps appends test steps of hooks to test steps of the test case
+func appendHookTestSteps(testSteps []*msgs.TestStep, hooks []*hookStep) []*msgs.TestStep {
+	for _, hs := range hooks {
+		testSteps = append(testSteps, &msgs.TestStep{Id: hs.id, HookId: hs.hook.id})
+	}
+
+	return testSteps
+}
+
+// stepMatchArguments describes groups captured by the step definition in the step's text
+func stepMatchArguments(def *stepDef, text string) []*msgs.StepMatchArgument {
+	arguments := []*msgs.StepMatchArgument{}
+
+	for _, m := range def.matchedGroups(text) {
+		argument := &msgs.StepMatchArgument{Group: &msgs.Group{Children: []*msgs.Group{}}}
+
+		if m.matched {
+			argument.Group.Start = int64(m.start)
+			argument.Group.Value = m.value
+		}
+
+		if m.parameterType != nil {
+			argument.ParameterTypeName = m.parameterType.name
+		}
+
+		arguments = append(arguments, argument)
+	}
+
+	return arguments
+}
+
+func timestamp(t time.Time) *msgs.Timestamp {
+	ts := msgs.GoTimeToTimestamp(t)
+
+	return &ts
+}
+
+func duration(d time.Duration) *msgs.Duration {
+	md := msgs.GoDurationToDuration(d)
+
+	return &md
+}
@@ -0,0 +1,145 @@
+package gobdd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMessagesOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"), WithMessagesOutput(buf))
+	suite.AddStep(`I add {int} and {int}`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	envelopes := decodeEnvelopes(t, buf)
+	require.NotEmpty(t, envelopes)
+	require.NotNil(t, envelopes[0].Meta)
+	require.Equal(t, "gobdd", envelopes[0].Meta.Implementation.Name)
+
+	last := envelopes[len(envelopes)-1]
+	require.NotNil(t, last.TestRunFinished)
+	require.True(t, last.TestRunFinished.Success)
+
+	var (
+		pickles, testCases, testCasesStarted, testCasesFinished int
+		statuses                                                []msgs.TestStepResultStatus
+		stepDefinitions                                         []*msgs.StepDefinitionPattern
+	)
+
+	pickleIDs := map[string]bool{}
+
+	for _, envelope := range envelopes {
+		switch {
+		case envelope.StepDefinition != nil:
+			stepDefinitions = append(stepDefinitions, envelope.StepDefinition.Pattern)
+		case envelope.Source != nil:
+			require.Equal(t, "features/outline.feature", envelope.Source.Uri)
+		case envelope.GherkinDocument != nil:
+			require.Equal(t, "features/outline.feature", envelope.GherkinDocument.Uri)
+		case envelope.Pickle != nil:
+			pickles++
+			pickleIDs[envelope.Pickle.Id] = true
+		case envelope.TestCase != nil:
+			testCases++
+			require.True(t, pickleIDs[envelope.TestCase.PickleId], "the pickle should be written before the test case")
+			require.Len(t, envelope.TestCase.TestSteps, 2)
+			require.Len(t, envelope.TestCase.TestSteps[0].StepDefinitionIds, 1)
+		case envelope.TestCaseStarted != nil:
+			testCasesStarted++
+		case envelope.TestStepFinished != nil:
+			statuses = append(statuses, envelope.TestStepFinished.TestStepResult.Status)
+		case envelope.TestCaseFinished != nil:
+			testCasesFinished++
+		}
+	}
+
+	// one step definition is reported for every registered step, even if parameter types have been applied
+	require.Equal(t, []*msgs.StepDefinitionPattern{
+		{Source: `I add {int} and {int}`, Type: msgs.StepDefinitionPatternType_REGULAR_EXPRESSION},
+		{Source: `the result should equal (\d+)`, Type: msgs.StepDefinitionPatternType_REGULAR_EXPRESSION},
+	}, stepDefinitions)
+	require.Equal(t, 2, pickles)
+	require.Equal(t, 2, testCases)
+	require.Equal(t, 2, testCasesStarted)
+	require.Equal(t, 2, testCasesFinished)
+	require.Equal(t, []msgs.TestStepResultStatus{
+		msgs.TestStepResultStatus_PASSED,
+		msgs.TestStepResultStatus_PASSED,
+		msgs.TestStepResultStatus_PASSED,
+		msgs.TestStepResultStatus_PASSED,
+	}, statuses)
+}
+
+func TestWithMessagesOutput_CucumberExpressions(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"), WithMessagesOutput(buf),
+		WithCucumberExpressions())
+	suite.AddStep(`I add {int} and {int}`, add)
+	suite.AddStep(`the result should equal {int}`, check)
+
+	suite.Run()
+
+	stepDefinitions := []*msgs.StepDefinitionPattern{}
+
+	for _, envelope := range decodeEnvelopes(t, buf) {
+		if envelope.StepDefinition != nil {
+			stepDefinitions = append(stepDefinitions, envelope.StepDefinition.Pattern)
+		}
+	}
+
+	require.Equal(t, []*msgs.StepDefinitionPattern{
+		{Source: `I add {int} and {int}`, Type: msgs.StepDefinitionPatternType_CUCUMBER_EXPRESSION},
+		{Source: `the result should equal {int}`, Type: msgs.StepDefinitionPatternType_CUCUMBER_EXPRESSION},
+	}, stepDefinitions)
+}
+
+func TestWithMessagesOutput_Background(t *testing.T) {
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/background.feature"), WithMessagesOutput(buf))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	pickleSteps := map[string]int{}
+
+	for _, envelope := range decodeEnvelopes(t, buf) {
+		if envelope.Pickle != nil {
+			pickleSteps[envelope.Pickle.Id] = len(envelope.Pickle.Steps)
+		}
+
+		if envelope.TestCase != nil {
+			require.Equal(t, pickleSteps[envelope.TestCase.PickleId], len(envelope.TestCase.TestSteps),
+				"every pickle step, including background steps, should be a test step")
+		}
+	}
+}
+
+func decodeEnvelopes(t *testing.T, buf *bytes.Buffer) []*msgs.Envelope {
+	t.Helper()
+
+	var envelopes []*msgs.Envelope
+
+	scanner := bufio.NewScanner(buf)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		envelope := &msgs.Envelope{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), envelope))
+
+		envelopes = append(envelopes, envelope)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return envelopes
+}
@@ -0,0 +1,343 @@
+package gobdd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// WithPrettyOutput configures a writer every executed scenario is printed to as colored Gherkin text,
+// together with locations of steps and matching step definitions, and a summary at the end of the run.
+// Colors are disabled when the NO_COLOR environment variable is set.
+func WithPrettyOutput(w io.Writer) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		_, noColor := os.LookupEnv("NO_COLOR")
+		wd, _ := os.Getwd()
+
+		options.listeners = append(options.listeners, &prettyFormatter{
+			w:         w,
+			colors:    !noColor,
+			wd:        wd,
+			scenarios: map[msgs.TestStepResultStatus]int{},
+			steps:     map[msgs.TestStepResultStatus]int{},
+		})
+	}
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorGrey   = "\033[90m"
+)
+
+var statusColors = map[msgs.TestStepResultStatus]string{
+	msgs.TestStepResultStatus_PASSED:    colorGreen,
+	msgs.TestStepResultStatus_SKIPPED:   colorCyan,
+	msgs.TestStepResultStatus_PENDING:   colorYellow,
+	msgs.TestStepResultStatus_UNDEFINED: colorYellow,
+	msgs.TestStepResultStatus_AMBIGUOUS: colorRed,
+	msgs.TestStepResultStatus_FAILED:    colorRed,
+}
+
+// summaryStatuses is the order statuses are listed in the summary
+var summaryStatuses = []msgs.TestStepResultStatus{
+	msgs.TestStepResultStatus_FAILED,
+	msgs.TestStepResultStatus_AMBIGUOUS,
+	msgs.TestStepResultStatus_UNDEFINED,
+	msgs.TestStepResultStatus_PENDING,
+	msgs.TestStepResultStatus_SKIPPED,
+	msgs.TestStepResultStatus_PASSED,
+}
+
+// prettyFormatter prints every test case when it's finished, so the output of parallel tests is not mixed
+type prettyFormatter struct {
+	baseListener
+
+	w      io.Writer
+	colors bool
+	// wd is the working directory, locations of step definitions are printed relative to it
+	wd string
+	// feature and rule are the last printed ones, so headers are printed only once
+	feature *msgs.Feature
+	rule    *msgs.Rule
+	// scenarios and steps count results by status
+	scenarios map[msgs.TestStepResultStatus]int
+	steps     map[msgs.TestStepResultStatus]int
+	// err holds the first error which occurred while writing
+	err error
+}
+
+func (f *prettyFormatter) testCaseFinished(tc *testCase) {
+	// only the last attempt of the retried test case is printed
+	if tc.willBeRetried {
+		return
+	}
+
+	f.scenarios[tc.status]++
+
+	var sb strings.Builder
+
+	f.writeHeaders(&sb, tc)
+
+	keyword := strings.TrimSpace(tc.scenario.Keyword)
+	indent := "  "
+
+	if tc.rule != nil {
+		indent += "  "
+	}
+
+	line := tc.scenario.Location.Line
+	if tc.row != nil {
+		line = tc.row.Location.Line
+	}
+
+	scenarioText := fmt.Sprintf("%s%s: %s", indent, keyword, tc.name())
+	if tc.flaky {
+		scenarioText += fmt.Sprintf(" (flaky, passed at the attempt %d)", tc.attempt+1)
+	}
+	stepTexts := make([]string, 0, len(tc.steps))
+	width := utf8.RuneCountInString(scenarioText)
+
+	for _, step := range tc.steps {
+		text := fmt.Sprintf("%s  %s%s", indent, step.step.Keyword, step.step.Text)
+		stepTexts = append(stepTexts, text)
+
+		if l := utf8.RuneCountInString(text); l > width {
+			width = l
+		}
+	}
+
+	writeScenario := func() {
+		f.writeTags(&sb, indent, tc.tags)
+		sb.WriteString(f.withComment(scenarioText, "", width, fmt.Sprintf("%s:%d", tc.uri, line)))
+	}
+
+	// backgrounds are written before the scenario, as in the feature file
+	var background *msgs.Background
+
+	for i, step := range tc.steps {
+		if step.background == nil && (i == 0 || background != nil) {
+			writeScenario()
+		}
+
+		if step.background != nil && step.background != background {
+			sb.WriteString(f.withComment(
+				fmt.Sprintf("%s%s: %s", indent, strings.TrimSpace(step.background.Keyword), step.background.Name), "", width,
+				fmt.Sprintf("%s:%d", tc.uri, step.background.Location.Line)))
+		}
+
+		background = step.background
+		f.steps[step.result.status]++
+
+		comment := fmt.Sprintf("%s:%d", tc.uri, step.step.Location.Line)
+		if step.def != nil {
+			comment += " -> " + f.relativePath(step.def.file) + fmt.Sprintf(":%d", step.def.line)
+		}
+
+		sb.WriteString(f.withComment(stepTexts[i], statusColors[step.result.status], width, comment))
+
+		f.writeStepArgument(&sb, indent+"    ", step)
+
+		if step.result.err != nil {
+			for _, line := range strings.Split(step.result.err.Error(), "\n") {
+				sb.WriteString(f.paint(statusColors[step.result.status], indent+"    "+line) + "\n")
+			}
+		}
+
+		f.writeHookErrors(&sb, indent+"    ", step.beforeHooks)
+		f.writeHookErrors(&sb, indent+"    ", step.afterHooks)
+	}
+
+	// the scenario has no steps or all of them come from backgrounds
+	if len(tc.steps) == 0 || background != nil {
+		writeScenario()
+	}
+
+	f.writeHookErrors(&sb, indent+"  ", tc.beforeHooks)
+	f.writeHookErrors(&sb, indent+"  ", tc.afterHooks)
+
+	sb.WriteString("\n")
+	f.write(sb.String())
+}
+
+// writeHookErrors writes errors of failed hooks, they're reported separately from errors of steps
+func (f *prettyFormatter) writeHookErrors(sb *strings.Builder, indent string, hooks []*hookStep) {
+	for _, hs := range hooks {
+		if hs.result.status != msgs.TestStepResultStatus_FAILED || hs.result.err == nil {
+			continue
+		}
+
+		for _, line := range strings.Split(hs.result.err.Error(), "\n") {
+			sb.WriteString(f.paint(statusColors[hs.result.status], indent+line) + "\n")
+		}
+	}
+}
+
+// testCaseSkipped counts test cases which are ignored because of their tags, they are not printed
+func (f *prettyFormatter) testCaseSkipped(tc *testCase) {
+	f.scenarios[tc.status]++
+}
+
+func (f *prettyFormatter) runFinished(run *testRun) error {
+	var sb strings.Builder
+
+	sb.WriteString(f.summary("scenario", "scenarios", f.scenarios) + "\n")
+	sb.WriteString(f.summary("step", "steps", f.steps) + "\n")
+	fmt.Fprintf(&sb, "%s\n", time.Since(run.started).Round(time.Millisecond))
+
+	f.write(sb.String())
+
+	return f.err
+}
+
+// writeHeaders writes the feature and the rule of the test case if they haven't been written yet
+func (f *prettyFormatter) writeHeaders(sb *strings.Builder, tc *testCase) {
+	if f.feature != tc.feature {
+		f.feature, f.rule = tc.feature, nil
+
+		f.writeTags(sb, "", tc.feature.Tags)
+		sb.WriteString(f.withComment(
+			fmt.Sprintf("%s: %s", tc.feature.Keyword, tc.feature.Name), "", 0,
+			fmt.Sprintf("%s:%d", tc.uri, tc.feature.Location.Line)))
+
+		if description := strings.TrimSpace(tc.feature.Description); description != "" {
+			sb.WriteString(tc.feature.Description + "\n")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	if tc.rule != nil && f.rule != tc.rule {
+		f.rule = tc.rule
+
+		f.writeTags(sb, "  ", tc.rule.Tags)
+		sb.WriteString(f.withComment(
+			fmt.Sprintf("  %s: %s", tc.rule.Keyword, tc.rule.Name), "", 0,
+			fmt.Sprintf("%s:%d", tc.uri, tc.rule.Location.Line)))
+		sb.WriteString("\n")
+	}
+}
+
+func (f *prettyFormatter) writeTags(sb *strings.Builder, indent string, tags []*msgs.Tag) {
+	if len(tags) == 0 {
+		return
+	}
+
+	sb.WriteString(f.paint(colorCyan, indent+strings.Join(tagNames(tags), " ")) + "\n")
+}
+
+// writeStepArgument writes the doc string or the data table of the step
+func (f *prettyFormatter) writeStepArgument(sb *strings.Builder, indent string, step *testStep) {
+	if docString := step.step.DocString; docString != nil {
+		sb.WriteString(indent + docString.Delimiter + docString.MediaType + "\n")
+
+		for _, line := range strings.Split(docString.Content, "\n") {
+			sb.WriteString(indent + line + "\n")
+		}
+
+		sb.WriteString(indent + docString.Delimiter + "\n")
+	}
+
+	if dataTable := step.step.DataTable; dataTable != nil {
+		widths := []int{}
+
+		for _, row := range dataTable.Rows {
+			for i, cell := range row.Cells {
+				if i >= len(widths) {
+					widths = append(widths, 0)
+				}
+
+				if l := utf8.RuneCountInString(cell.Value); l > widths[i] {
+					widths[i] = l
+				}
+			}
+		}
+
+		for _, row := range dataTable.Rows {
+			sb.WriteString(indent + "|")
+
+			for i, cell := range row.Cells {
+				fmt.Fprintf(sb, " %s%s |", cell.Value, strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell.Value)))
+			}
+
+			sb.WriteString("\n")
+		}
+	}
+}
+
+// withComment paints the text and appends the comment aligned to the given width
+func (f *prettyFormatter) withComment(text, color string, width int, comment string) string {
+	padding := ""
+	if l := utf8.RuneCountInString(text); l < width {
+		padding = strings.Repeat(" ", width-l)
+	}
+
+	return f.paint(color, text) + padding + " " + f.paint(colorGrey, "# "+comment) + "\n"
+}
+
+// summary describes counted results, like "3 scenarios (1 failed, 2 passed)"
+func (f *prettyFormatter) summary(singular, plural string, counts map[msgs.TestStepResultStatus]int) string {
+	total := 0
+	details := []string{}
+
+	for _, status := range summaryStatuses {
+		if counts[status] == 0 {
+			continue
+		}
+
+		total += counts[status]
+		details = append(details,
+			f.paint(statusColors[status], fmt.Sprintf("%d %s", counts[status], strings.ToLower(status.String()))))
+	}
+
+	name := plural
+	if total == 1 {
+		name = singular
+	}
+
+	if total == 0 {
+		return fmt.Sprintf("0 %s", name)
+	}
+
+	return fmt.Sprintf("%d %s (%s)", total, name, strings.Join(details, ", "))
+}
+
+// paint colors the text, if colors are enabled
+func (f *prettyFormatter) paint(color, text string) string {
+	if !f.colors || color == "" {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// relativePath returns the path relative to the working directory if the file is inside it
+func (f *prettyFormatter) relativePath(path string) string {
+	if f.wd == "" {
+		return path
+	}
+
+	rel, err := filepath.Rel(f.wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+func (f *prettyFormatter) write(s string) {
+	if f.err != nil {
+		return
+	}
+
+	_, f.err = io.WriteString(f.w, s)
+}
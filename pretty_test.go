@@ -0,0 +1,108 @@
+package gobdd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPrettyOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/datatable.feature"), WithPrettyOutput(buf))
+	suite.AddStep(`I concat all the columns and row together using {text} to separate the columns`, concatTable)
+	suite.AddStep(`the result should equal argument:`, checkt)
+
+	suite.Run()
+
+	lines := strings.Split(buf.String(), "\n")
+
+	require.Equal(t, "Feature: dataTable feature # features/datatable.feature:1", lines[0])
+	require.Regexp(t, `^  Scenario: compare text with dataTable +# features/datatable.feature:2$`, lines[2])
+	require.Regexp(t,
+		`^    When I concat all the columns and row together using " - " to separate the columns `+
+			`# features/datatable.feature:3 -> pretty_test.go:\d+$`, lines[3])
+	require.Equal(t, "      | r1c1 | r1c2 | r1c3 |", lines[4])
+	require.Regexp(t, `^    Then the result should equal argument: +# features/datatable.feature:7 -> pretty_test.go:\d+$`,
+		lines[7])
+	require.Equal(t, `      """`, lines[8])
+	require.Equal(t, "      r1c1 - r1c2 - r1c3", lines[9])
+	require.Equal(t, "1 scenario (1 passed)", lines[14])
+	require.Equal(t, "2 steps (2 passed)", lines[15])
+}
+
+func TestWithPrettyOutput_Background(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	buf := &bytes.Buffer{}
+	suite := NewSuite(t, WithFeaturesPath("features/background.feature"), WithPrettyOutput(buf))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`I concat word {word} and text {text}`, concat)
+	suite.AddStep(`the result should equal text {text}`, checkt)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	require.Regexp(t, `  Rule: adding and concat # features/background.feature:8\n\n`+
+		`    Background: adding +# features/background.feature:2\n`+
+		`      When I add 1 and 2 +# features/background.feature:3 -> pretty_test.go:\d+\n`+
+		`    Background: concat +# features/background.feature:9\n`, buf.String())
+	require.Contains(t, buf.String(), "2 scenarios (2 passed)\n6 steps (6 passed)\n")
+}
+
+func TestPrettyFormatter_Failure(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &prettyFormatter{
+		w:         buf,
+		colors:    true,
+		scenarios: map[msgs.TestStepResultStatus]int{},
+		steps:     map[msgs.TestStepResultStatus]int{},
+	}
+
+	location := &msgs.Location{Line: 3}
+	f.testCaseFinished(&testCase{
+		uri:      "features/failure.feature",
+		feature:  &msgs.Feature{Keyword: "Feature", Name: "failures", Location: &msgs.Location{Line: 1}},
+		scenario: &msgs.Scenario{Keyword: "Scenario", Name: "the scenario fails", Location: &msgs.Location{Line: 2}},
+		status:   msgs.TestStepResultStatus_FAILED,
+		steps: []*testStep{
+			{
+				step: &msgs.Step{Keyword: "Then ", Text: "the result should equal 3", Location: location},
+				result: stepResult{
+					status: msgs.TestStepResultStatus_FAILED,
+					err:    errors.New("expected 3 but 4 received"),
+				},
+			},
+			{
+				step:   &msgs.Step{Keyword: "And ", Text: "something undefined", Location: location},
+				result: stepResult{status: msgs.TestStepResultStatus_UNDEFINED},
+			},
+		},
+		afterHooks: []*hookStep{
+			{
+				result: stepResult{
+					status: msgs.TestStepResultStatus_FAILED,
+					err:    errors.New("cannot close the connection"),
+				},
+			},
+		},
+	})
+	f.testCaseSkipped(&testCase{status: msgs.TestStepResultStatus_SKIPPED})
+
+	require.NoError(t, f.runFinished(&testRun{started: time.Now()}))
+
+	output := buf.String()
+	require.Contains(t, output, colorRed+"    Then the result should equal 3"+colorReset)
+	require.Contains(t, output, colorRed+"      expected 3 but 4 received"+colorReset)
+	require.Contains(t, output, colorYellow+"    And something undefined"+colorReset)
+	require.Contains(t, output, colorRed+"    cannot close the connection"+colorReset)
+	require.Contains(t, output, "2 scenarios ("+colorRed+"1 failed"+colorReset+", "+colorCyan+"1 skipped"+colorReset+")")
+	require.Contains(t, output,
+		"2 steps ("+colorRed+"1 failed"+colorReset+", "+colorYellow+"1 undefined"+colorReset+")")
+}
@@ -0,0 +1,255 @@
+package gobdd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// retryTag matches tags configuring how many times the failed scenario is retried, like @retry(3)
+var retryTag = regexp.MustCompile(`^@retry\((.*)\)$`)
+
+// WithRetries configures how many times failed scenarios are retried, unless the scenario is tagged with
+// a tag like @retry(3). The tag can be added to the feature, the rule or the Examples block as well.
+// Every attempt starts with a fresh Context and runs hooks again. The test fails only when the last attempt fails,
+// scenarios which pass after failed attempts are reported as flaky. Failed scenarios are not retried by default.
+func WithRetries(retries int) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.retries = retries
+	}
+}
+
+// retries returns the number of retries configured by the last @retry tag or WithRetries
+func (s *Suite) retries(tags []*msgs.Tag) (int, error) {
+	retries := s.options.retries
+
+	for _, tag := range tags {
+		m := retryTag.FindStringSubmatch(tag.Name)
+		if m == nil {
+			continue
+		}
+
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("the tag %s is incorrect: the number of retries should be a non-negative integer",
+				tag.Name)
+		}
+
+		retries = n
+	}
+
+	return retries, nil
+}
+
+// runTestCaseWithRetries runs the test case until it passes or the limit of retries is reached. Every attempt
+// gets a copy of the context. Attempts which may be retried are executed with the attemptT, so their failures
+// are logged, but they don't fail the test.
+func (s *Suite) runTestCaseWithRetries(run *testRun, ctx Context, t *testing.T, tc *testCase) {
+	retries, err := s.retries(tc.allTags)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.options.dryRun {
+		retries = 0
+	}
+
+	if step := tc.stepNeedingTestingT(); retries > 0 && step != nil {
+		t.Logf("the scenario %s is not retried, because the step %s%s requires %s",
+			tc.name(), step.step.Keyword, step.step.Text, reflect.TypeOf(step.def.f).In(0))
+
+		retries = 0
+	}
+
+	tc.retries = retries
+
+	for tc.attempt < tc.retries {
+		attempt := &attemptT{T: t}
+		attempt.run(func(t TestingT) {
+			s.runTestCase(run, ctx.Clone(), t, tc)
+		})
+
+		if !tc.willBeRetried {
+			return
+		}
+
+		t.Logf("the attempt %d of the scenario %s failed, retrying", tc.attempt+1, tc.name())
+
+		tc = tc.retry()
+	}
+
+	s.runTestCase(run, ctx.Clone(), t, tc)
+}
+
+// stepNeedingTestingT returns the step whose function takes the concrete type, like *testing.T,
+// instead of the StepTest, such steps cannot be executed with the attemptT
+func (tc *testCase) stepNeedingTestingT() *testStep {
+	for _, step := range tc.steps {
+		if step.def == nil {
+			continue
+		}
+
+		if in := reflect.TypeOf(step.def.f).In(0); in != stdContextType && in.Kind() != reflect.Interface {
+			return step
+		}
+	}
+
+	return nil
+}
+
+// retry creates the next attempt of the test case with results of steps reset
+func (tc *testCase) retry() *testCase {
+	next := *tc
+	next.attempt++
+	next.willBeRetried = false
+	next.err = nil
+	next.steps = make([]*testStep, 0, len(tc.steps))
+	next.beforeHooks = retryHookSteps(tc.beforeHooks, nil)
+	next.afterHooks = retryHookSteps(tc.afterHooks, nil)
+
+	for _, step := range tc.steps {
+		s := *step
+		s.result = stepResult{}
+		s.attachments = nil
+		s.beforeHooks = retryHookSteps(step.beforeHooks, &s)
+		s.afterHooks = retryHookSteps(step.afterHooks, &s)
+
+		next.steps = append(next.steps, &s)
+	}
+
+	return &next
+}
+
+// attemptT executes the attempt of the test case which may be retried. Failures and skips are recorded and logged
+// instead of being reported to the test. Subtests are executed in new goroutines, so FailNow and SkipNow stop
+// only the subtest, like with testing.T.
+type attemptT struct {
+	*testing.T
+	mu      sync.Mutex
+	failed  bool
+	skipped bool
+}
+
+// run runs f in a new goroutine and waits until it's finished, it tells whether f passed
+func (a *attemptT) run(f func(t TestingT)) bool {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		f(a)
+	}()
+	<-done
+
+	return !a.Failed()
+}
+
+// subtest runs f with a new attemptT, the failure of the subtest fails the attempt
+func (a *attemptT) subtest(f func(t TestingT)) bool {
+	sub := &attemptT{T: a.T}
+	if !sub.run(f) {
+		a.Fail()
+
+		return false
+	}
+
+	return true
+}
+
+func (a *attemptT) Error(args ...interface{}) {
+	a.T.Helper()
+	a.T.Log(args...)
+	a.Fail()
+}
+
+func (a *attemptT) Errorf(format string, args ...interface{}) {
+	a.T.Helper()
+	a.T.Logf(format, args...)
+	a.Fail()
+}
+
+func (a *attemptT) Fatal(args ...interface{}) {
+	a.T.Helper()
+	a.T.Log(args...)
+	a.FailNow()
+}
+
+func (a *attemptT) Fatalf(format string, args ...interface{}) {
+	a.T.Helper()
+	a.T.Logf(format, args...)
+	a.FailNow()
+}
+
+func (a *attemptT) Fail() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.failed = true
+}
+
+func (a *attemptT) FailNow() {
+	a.Fail()
+	runtime.Goexit()
+}
+
+func (a *attemptT) Failed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.failed
+}
+
+func (a *attemptT) Skip(args ...interface{}) {
+	a.T.Helper()
+	a.T.Log(args...)
+	a.SkipNow()
+}
+
+func (a *attemptT) Skipf(format string, args ...interface{}) {
+	a.T.Helper()
+	a.T.Logf(format, args...)
+	a.SkipNow()
+}
+
+func (a *attemptT) SkipNow() {
+	a.mu.Lock()
+	a.skipped = true
+	a.mu.Unlock()
+
+	runtime.Goexit()
+}
+
+func (a *attemptT) Skipped() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.skipped
+}
+
+// testingTOf returns the test stored in the context under TestingTKey. Attempts which may be retried store
+// the *testing.T of the scenario, so the attemptT is never visible to steps and hooks.
+func testingTOf(t TestingT) TestingT {
+	if a, ok := t.(*attemptT); ok {
+		return a.T
+	}
+
+	return t
+}
+
+// runSubtest runs f as a subtest of t. Subtests of attempts which may be retried don't use testing.T.Run,
+// so their failures don't fail the test.
+func runSubtest(t TestingT, name string, f func(t TestingT)) bool {
+	if a, ok := t.(*attemptT); ok {
+		return a.subtest(f)
+	}
+
+	return t.Run(name, func(t *testing.T) {
+		f(t)
+	})
+}
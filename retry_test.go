@@ -0,0 +1,133 @@
+package gobdd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+type attemptsFormatter struct {
+	BaseFormatter
+
+	scenarios []ScenarioEvent
+}
+
+func (f *attemptsFormatter) ScenarioFinished(event ScenarioEvent) {
+	f.scenarios = append(f.scenarios, event)
+}
+
+func TestRetries(t *testing.T) {
+	calls, beforeScenarios := 0, 0
+	testingTs := map[string]bool{}
+	formatter := &attemptsFormatter{}
+	junit := &bytes.Buffer{}
+
+	suite := NewSuite(t, WithFeaturesPath("features/retry.feature"), WithFormatters(formatter),
+		WithJUnitReport(junit),
+		WithBeforeScenario(func(ctx Context) {
+			beforeScenarios++
+
+			value, _ := ctx.Get(TestingTKey{})
+			testingTs[fmt.Sprintf("%T", value)] = true
+		}))
+	suite.AddStep(`the context is fresh`, func(t StepTest, ctx Context) {
+		if _, err := ctx.Get("called"); err == nil {
+			t.Fatal("the context is not fresh")
+		}
+	})
+	suite.AddStep(`the service is called`, func(t StepTest, ctx Context) {
+		ctx.Set("called", true)
+		calls++
+
+		value, _ := ctx.Get(TestingTKey{})
+		testingTs[fmt.Sprintf("%T", value)] = true
+	})
+	suite.AddStep(`the service responds`, func(t StepTest, ctx Context) error {
+		switch calls {
+		case 1:
+			t.Fatal("the service is unavailable")
+		case 2: // nolint:mnd
+			return errors.New("the service is still unavailable")
+		}
+
+		return nil
+	})
+
+	suite.Run()
+
+	require.Equal(t, 3, calls)
+	require.Equal(t, 4, beforeScenarios)
+	require.Equal(t, map[string]bool{"*testing.T": true}, testingTs, "retried attempts store *testing.T as well")
+	require.Len(t, formatter.scenarios, 4)
+
+	for i, event := range formatter.scenarios[:3] {
+		require.Equal(t, i, event.Attempt)
+		require.Equal(t, i < 2, event.WillBeRetried)
+		require.Equal(t, i == 2, event.Flaky)
+	}
+
+	require.Equal(t, msgs.TestStepResultStatus_FAILED, formatter.scenarios[0].Status)
+	require.EqualError(t, formatter.scenarios[1].Err, "the service is still unavailable")
+	require.Equal(t, msgs.TestStepResultStatus_PASSED, formatter.scenarios[2].Status)
+	require.False(t, formatter.scenarios[3].Flaky)
+
+	require.Equal(t, 2, bytes.Count(junit.Bytes(), []byte("<flakyFailure ")))
+	require.Equal(t, 2, bytes.Count(junit.Bytes(), []byte("<testcase ")))
+}
+
+func TestSuite_Retries(t *testing.T) {
+	tag := func(name string) *msgs.Tag {
+		return &msgs.Tag{Name: name}
+	}
+
+	suite := NewSuite(t, WithRetries(1))
+
+	retries, err := suite.retries([]*msgs.Tag{tag("@slow")})
+	require.NoError(t, err)
+	require.Equal(t, 1, retries)
+
+	retries, err = suite.retries([]*msgs.Tag{tag("@retry(3)"), tag("@retry(0)")})
+	require.NoError(t, err)
+	require.Equal(t, 0, retries)
+
+	for _, name := range []string{"@retry(many)", "@retry(-1)"} {
+		_, err = suite.retries([]*msgs.Tag{tag(name)})
+		require.EqualError(t, err,
+			"the tag "+name+" is incorrect: the number of retries should be a non-negative integer")
+	}
+}
+
+func TestAttemptT(t *testing.T) {
+	attempt := &attemptT{T: t}
+	results := []bool{}
+
+	passed := attempt.run(func(t TestingT) {
+		results = append(results, runSubtest(t, "passes", func(TestingT) {}))
+		results = append(results, runSubtest(t, "skips", func(t TestingT) {
+			t.Skip("the step is skipped")
+		}))
+		results = append(results, runSubtest(t, "fails", func(t TestingT) {
+			t.Fatal("the step failed")
+		}))
+		results = append(results, runSubtest(t, "after the failure", func(TestingT) {}))
+	})
+
+	require.False(t, passed)
+	require.Equal(t, []bool{true, true, false, true}, results)
+	require.False(t, t.Failed())
+}
+
+func TestTestCase_StepNeedingTestingT(t *testing.T) {
+	tc := &testCase{steps: []*testStep{
+		{},
+		{def: &stepDef{f: func(StepTest, Context) {}}},
+	}}
+	require.Nil(t, tc.stepNeedingTestingT())
+
+	tc.steps = append(tc.steps, &testStep{def: &stepDef{f: func(*testing.T, Context) {}}})
+	require.Equal(t, tc.steps[2], tc.stepNeedingTestingT())
+}
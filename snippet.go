@@ -0,0 +1,104 @@
+package gobdd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// snippetLiteral matches literals in the step's text which are replaced with parameter types in snippets
+var snippetLiteral = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|-?\d*\.\d+|-?\d+`)
+
+// cucumberExpressionSpecial matches characters which have to be escaped in Cucumber Expressions
+var cucumberExpressionSpecial = regexp.MustCompile(`[(){}/\\]`)
+
+// stepSnippet generates the code registering a step definition for the undefined step.
+// Quoted strings are replaced with {text}, numbers with {int} or {float}. The rest of the text is escaped
+// as a Cucumber Expression or as a regular expression, depending on cucumberExpressions.
+func stepSnippet(step *msgs.Step, cucumberExpressions bool) string {
+	var expr strings.Builder
+
+	quote := regexp.QuoteMeta
+	if cucumberExpressions {
+		quote = func(s string) string {
+			return cucumberExpressionSpecial.ReplaceAllString(s, `\$0`)
+		}
+	}
+
+	params := []string{"t gobdd.StepTest", "ctx gobdd.Context"}
+	text := step.Text
+	last := 0
+
+	for _, loc := range snippetLiteral.FindAllStringIndex(text, -1) {
+		// literals being a part of a word, like in "h2o" or "don't", are left as they are
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(text[loc[1]:])
+
+		if !isSnippetBoundary(before) || !isSnippetBoundary(after) {
+			continue
+		}
+
+		literal := text[loc[0]:loc[1]]
+		paramType, goType := "{text}", "string"
+
+		switch {
+		case literal[0] == '"' || literal[0] == '\'':
+		case strings.Contains(literal, "."):
+			paramType, goType = "{float}", "float64"
+		default:
+			paramType, goType = "{int}", "int"
+		}
+
+		expr.WriteString(quote(text[last:loc[0]]))
+		expr.WriteString(paramType)
+		params = append(params, fmt.Sprintf("arg%d %s", len(params)-1, goType))
+		last = loc[1]
+	}
+
+	expr.WriteString(quote(text[last:]))
+
+	if step.DocString != nil {
+		params = append(params, "docString string")
+	}
+
+	if step.DataTable != nil {
+		params = append(params, "table msgs.DataTable")
+	}
+
+	quoted := "`" + expr.String() + "`"
+	if strings.Contains(expr.String(), "`") {
+		quoted = strconv.Quote(expr.String())
+	}
+
+	return fmt.Sprintf("suite.AddStep(%s, func(%s) {\n\tt.Fatal(\"not implemented\")\n})",
+		quoted, strings.Join(params, ", "))
+}
+
+// isSnippetBoundary tells whether the character next to a literal doesn't make it a part of a word.
+// utf8.RuneError is returned at the beginning and at the end of the text.
+func isSnippetBoundary(r rune) bool {
+	return r == utf8.RuneError || !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.'
+}
+
+// undefinedStepsSnippets generates snippets for all undefined steps, every snippet is listed only once
+func undefinedStepsSnippets(steps []*msgs.Step, cucumberExpressions bool) string {
+	snippets := []string{}
+	seen := map[string]bool{}
+
+	for _, step := range steps {
+		snippet := stepSnippet(step, cucumberExpressions)
+		if seen[snippet] {
+			continue
+		}
+
+		seen[snippet] = true
+		snippets = append(snippets, snippet)
+	}
+
+	return strings.Join(snippets, "\n\n")
+}
@@ -0,0 +1,117 @@
+package gobdd
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepSnippet(t *testing.T) {
+	testCases := map[string]struct {
+		step                *msgs.Step
+		cucumberExpressions bool
+		expected            string
+	}{
+		"no parameters": {
+			step: &msgs.Step{Text: "the user is logged in"},
+			expected: "suite.AddStep(`the user is logged in`, func(t gobdd.StepTest, ctx gobdd.Context) {\n" +
+				"\tt.Fatal(\"not implemented\")\n})",
+		},
+		"numbers": {
+			step: &msgs.Step{Text: "I add 1 and -2.5"},
+			expected: "suite.AddStep(`I add {int} and {float}`, " +
+				"func(t gobdd.StepTest, ctx gobdd.Context, arg1 int, arg2 float64) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"texts": {
+			step: &msgs.Step{Text: `I concat "Hello" and 'World'`},
+			expected: "suite.AddStep(`I concat {text} and {text}`, " +
+				"func(t gobdd.StepTest, ctx gobdd.Context, arg1 string, arg2 string) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"literals being a part of a word": {
+			step: &msgs.Step{Text: "I don't drink h2o"},
+			expected: "suite.AddStep(`I don't drink h2o`, func(t gobdd.StepTest, ctx gobdd.Context) {\n" +
+				"\tt.Fatal(\"not implemented\")\n})",
+		},
+		"regexp characters": {
+			step: &msgs.Step{Text: "the price (in $) is 5"},
+			expected: "suite.AddStep(`the price \\(in \\$\\) is {int}`, " +
+				"func(t gobdd.StepTest, ctx gobdd.Context, arg1 int) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"cucumber expression characters": {
+			step:                &msgs.Step{Text: "the price (in $/{unit}) is 5 today."},
+			cucumberExpressions: true,
+			expected: "suite.AddStep(`the price \\(in $\\/\\{unit\\}\\) is {int} today.`, " +
+				"func(t gobdd.StepTest, ctx gobdd.Context, arg1 int) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"backticks": {
+			step: &msgs.Step{Text: "I run `ls`"},
+			expected: "suite.AddStep(\"I run `ls`\", func(t gobdd.StepTest, ctx gobdd.Context) {\n" +
+				"\tt.Fatal(\"not implemented\")\n})",
+		},
+		"doc string and data table": {
+			step: &msgs.Step{
+				Text:      "the request",
+				DocString: &msgs.DocString{Content: "{}"},
+				DataTable: &msgs.DataTable{},
+			},
+			expected: "suite.AddStep(`the request`, " +
+				"func(t gobdd.StepTest, ctx gobdd.Context, docString string, table msgs.DataTable) {\n" +
+				"\tt.Fatal(\"not implemented\")\n})",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, stepSnippet(testCase.step, testCase.cucumberExpressions))
+		})
+	}
+}
+
+func TestUndefinedStepsSnippets(t *testing.T) {
+	snippets := undefinedStepsSnippets([]*msgs.Step{
+		{Text: "I add 1 and 2"},
+		{Text: "I add 5 and 5"},
+		{Text: "the result should equal 3"},
+	}, false)
+
+	require.Equal(t, "suite.AddStep(`I add {int} and {int}`, "+
+		"func(t gobdd.StepTest, ctx gobdd.Context, arg1 int, arg2 int) {\n\tt.Fatal(\"not implemented\")\n})\n\n"+
+		"suite.AddStep(`the result should equal {int}`, "+
+		"func(t gobdd.StepTest, ctx gobdd.Context, arg1 int) {\n\tt.Fatal(\"not implemented\")\n})", snippets)
+}
+
+func TestStepSnippet_Registration(t *testing.T) {
+	texts := []string{
+		"I pay 5 dollars.",
+		"the price (in $) is 5",
+		"the path a/b\\c has 5 files",
+		"the template {name} is used 5 times?",
+		"I pay 5 dollars^*+[]|",
+	}
+
+	for _, cucumberExpressions := range []bool{false, true} {
+		for _, text := range texts {
+			snippet := stepSnippet(&msgs.Step{Text: text}, cucumberExpressions)
+			expr, err := strconv.Unquote(strings.TrimPrefix(strings.Split(snippet, ", func(")[0], "suite.AddStep("))
+			require.NoError(t, err)
+
+			options := []func(*SuiteOptions){WithFullTextStepMatching()}
+			if cucumberExpressions {
+				options = append(options, WithCucumberExpressions())
+			}
+
+			suite := NewSuite(t, options...)
+			suite.AddStep(expr, func(StepTest, Context, int) {})
+			require.False(t, suite.hasStepErrors, "the snippet %s cannot be registered", snippet)
+
+			def, _, err := suite.findStepDef(text)
+			require.NoError(t, err, "the snippet %s doesn't match the step %q", snippet, text)
+			require.Equal(t, "5", def.matchedGroups(text)[0].value)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gobdd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	stdContextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// contextKey is used to get the gobdd Context from the context.Context passed to step functions
+type contextKey struct{}
+
+// stdContextKey is used to store the context.Context returned by the last step of the scenario
+type stdContextKey struct{}
+
+// FromContext returns the gobdd Context of the scenario from the context.Context passed to step functions.
+//
+//	suite.AddStep(`the user is created`, func(ctx context.Context) error {
+//		bddCtx, _ := gobdd.FromContext(ctx)
+//		...
+//	})
+func FromContext(ctx context.Context) (Context, bool) {
+	c, ok := ctx.Value(contextKey{}).(Context)
+
+	return c, ok
+}
+
+// stepContext is the context.Context passed to step functions. Values of the gobdd Context, like the scenario
+// under ScenarioKey, are available through Value.
+type stepContext struct {
+	context.Context
+	ctx Context
+}
+
+func (c stepContext) Value(key interface{}) interface{} {
+	if key == (contextKey{}) {
+		return c.ctx
+	}
+
+	if key != nil && reflect.TypeOf(key).Comparable() {
+		if v, ok := c.ctx.values[key]; ok {
+			return v
+		}
+	}
+
+	return c.Context.Value(key)
+}
+
+// detachedContext keeps values of the context but not its deadline and cancellation, so the context returned
+// by a step can be passed to following steps after the step's own context is cancelled
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// newStepContext creates the context.Context for the step. It carries values returned by previous steps and
+// the gobdd Context. The context is cancelled when the timeout of the step or the test's deadline, set with
+// the -timeout flag, is exceeded.
+func newStepContext(ctx Context, t TestingT, timeout time.Duration) (context.Context, context.CancelFunc) {
+	parent, ok := ctx.values[stdContextKey{}].(context.Context)
+	if !ok {
+		parent = context.Background()
+	}
+
+	stepCtx := context.Context(stepContext{Context: parent, ctx: ctx})
+
+	if deadline, ok := testDeadline(t); ok && (timeout <= 0 || time.Until(deadline) < timeout) {
+		return context.WithDeadline(stepCtx, deadline)
+	}
+
+	if timeout > 0 {
+		return context.WithTimeout(stepCtx, timeout)
+	}
+
+	return context.WithCancel(stepCtx)
+}
+
+// testDeadline returns the deadline of the test, if any
+func testDeadline(t TestingT) (time.Time, bool) {
+	if r, ok := t.(*stepRecorder); ok {
+		t = r.TestingT
+	}
+
+	if a, ok := t.(*attemptT); ok {
+		t = a.T
+	}
+
+	if tt, ok := t.(*testing.T); ok {
+		return tt.Deadline()
+	}
+
+	return time.Time{}, false
+}
+
+// contextArguments returns the number of arguments the step function takes before arguments of the step.
+// It's 1 when the function takes only the context.Context, 2 for the StepTest and a context.
+func contextArguments(f reflect.Type) int {
+	if f.NumIn() > 0 && f.In(0) == stdContextType {
+		return 1
+	}
+
+	return contextArgumentsNumber
+}
+
+// handleResults reports the error returned by the step function, if any, see reportStepError, and keeps
+// the returned context.Context for following steps of the scenario. Other returned values are ignored.
+func handleResults(ctx Context, t TestingT, results []reflect.Value) {
+	if r, ok := t.(*stepRecorder); ok && !r.active() {
+		// the step has timed out, the following steps may be executed already
+		return
+	}
+
+	for _, result := range results {
+		switch {
+		case result.Type() == stdContextType && !result.IsNil():
+			ctx.Set(stdContextKey{}, context.Context(detachedContext{parent: result.Interface().(context.Context)}))
+		case result.Type() == errorType && !result.IsNil():
+			reportStepError(t, result.Interface().(error))
+		}
+	}
+}
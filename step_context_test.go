@@ -0,0 +1,116 @@
+package gobdd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+type requestIDKey struct{}
+
+func TestStepContext(t *testing.T) {
+	var (
+		requestCtxErr error
+		requestID     interface{}
+	)
+
+	suite := NewSuite(t, WithFeaturesPath("features/std-context.feature"))
+	suite.AddStep(`the request ID {text} is added to the context`, func(ctx context.Context, id string) (
+		context.Context, error,
+	) {
+		return context.WithValue(ctx, requestIDKey{}, id), nil
+	})
+	suite.AddStep(`the request is sent`, func(t StepTest, ctx context.Context) {
+		requestCtxErr = ctx.Err()
+		requestID = ctx.Value(requestIDKey{})
+
+		bddCtx, ok := FromContext(ctx)
+		require.True(t, ok)
+		bddCtx.Set("sent", true)
+	})
+	suite.AddStep(`the request ID is {text}`, func(ctx context.Context, id string) error {
+		bddCtx, _ := FromContext(ctx)
+		if sent, _ := bddCtx.Get("sent", false); sent != true {
+			return errors.New("the request hasn't been sent")
+		}
+
+		if ctx.Value(requestIDKey{}) != id {
+			return errors.New("unexpected request ID")
+		}
+
+		return nil
+	})
+	suite.AddStep(`the scenario {text} is available in the context`, func(ctx context.Context, name string) error {
+		scenario, ok := ctx.Value(ScenarioKey{}).(*msgs.Scenario)
+		if !ok || scenario.Name != name {
+			return errors.New("the scenario isn't available")
+		}
+
+		return nil
+	})
+
+	suite.Run()
+
+	require.NoError(t, requestCtxErr)
+	require.Equal(t, "abc", requestID)
+}
+
+func TestStepContext_CancelledAfterStep(t *testing.T) {
+	var stepCtx context.Context
+
+	def := stepDef{f: func(ctx context.Context) context.Context {
+		stepCtx = ctx
+
+		return context.WithValue(ctx, requestIDKey{}, "abc")
+	}}
+	ctx := NewContext()
+
+	require.NoError(t, def.run(ctx, &mockTester{}, nil, 0))
+	require.ErrorIs(t, stepCtx.Err(), context.Canceled)
+
+	var (
+		err       error
+		requestID interface{}
+	)
+
+	def = stepDef{f: func(ctx context.Context) {
+		err = ctx.Err()
+		requestID = ctx.Value(requestIDKey{})
+	}}
+
+	require.NoError(t, def.run(ctx, &mockTester{}, nil, 0))
+	require.NoError(t, err)
+	require.Equal(t, "abc", requestID)
+}
+
+func TestStepContext_ReturnedError(t *testing.T) {
+	def := stepDef{f: func(context.Context) (context.Context, error) {
+		return nil, errors.New("the request failed")
+	}}
+	tester := &mockTester{}
+
+	require.NoError(t, def.run(NewContext(), tester, nil, 0))
+	require.Equal(t, []string{"the request failed"}, tester.fatalMessages)
+}
+
+func TestValidateStepFunc_StdContext(t *testing.T) {
+	testCases := map[string]interface{}{
+		"context.Context":               func(context.Context) {},
+		"context.Context with argument": func(context.Context, int) error { return nil },
+		"StepTest and context.Context":  func(StepTest, context.Context, string) {},
+		"returning context.Context":     func(context.Context) (context.Context, error) { return nil, nil },
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			if err := validateStepFunc(testCase); err != nil {
+				t.Errorf("the test should NOT fail for the function: %s", err)
+			}
+		})
+	}
+}
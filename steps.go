@@ -2,32 +2,268 @@ package gobdd
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 )
 
-func validateStepFunc(f interface{}) error {
+var (
+	// ErrPending is returned by steps which are not implemented yet. Such steps are reported as pending
+	// and fail the test.
+	ErrPending = errors.New("the step is pending")
+	// ErrSkip is returned by steps to skip the test, like calling StepTest.Skip.
+	ErrSkip = errors.New("the step is skipped")
+)
+
+// validateStepFunc checks the signature of the step function. The function takes the StepTest and the Context,
+// the StepTest and context.Context or only context.Context. Following arguments have to be convertible from
+// the step's text, the doc string or the data table. Parameters of the Cucumber Expression, if any, are checked
+// against types of values their parameter types return.
+func validateStepFunc(f interface{}, parameters ...expressionParameter) error {
 	value := reflect.ValueOf(f)
 	if value.Kind() != reflect.Func {
 		return errors.New("the parameter should be a function")
 	}
 
-	if value.Type().NumIn() < contextArgumentsNumber {
+	leading := contextArguments(value.Type())
+
+	if leading == contextArgumentsNumber {
+		if err := validateContextArguments(value.Type()); err != nil {
+			return err
+		}
+	}
+
+	for i := leading; i < value.Type().NumIn(); i++ {
+		check := checkParamType
+		if j := i - leading; j < len(parameters) {
+			check = parameters[j].parameterType.checkType
+		}
+
+		err := check(value.Type().In(i))
+
+		// the last argument can receive the doc string, which can be decoded to more types
+		if err != nil && i == value.Type().NumIn()-1 && checkDocStringType(value.Type().In(i)) == nil {
+			err = nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("the argument %d cannot be converted: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// validateContextArguments checks whether the function takes the StepTest and the Context or context.Context
+func validateContextArguments(f reflect.Type) error {
+	if f.NumIn() < contextArgumentsNumber {
 		return errors.New("the function should have StepTest and Context as the first argument")
 	}
 
-	val := value.Type().In(0)
+	val := f.In(0)
 
 	testingInterface := reflect.TypeOf((*StepTest)(nil)).Elem()
 	if !val.Implements(testingInterface) {
 		return errors.New("the function should have the StepTest as the first argument")
 	}
 
-	val = value.Type().In(1)
+	val = f.In(1)
 
 	n := val.ConvertibleTo(reflect.TypeOf((*Context)(nil)).Elem())
-	if !n {
+	if !n && val != stdContextType {
 		return errors.New("the function should have Context as the second argument")
 	}
 
 	return nil
 }
+
+// stepRecorder wraps the TestingT passed to a step function and records the outcome of the step
+type stepRecorder struct {
+	TestingT
+	mu sync.Mutex
+	// stopped is true when the step has timed out, the step function may still be running in another goroutine,
+	// but its calls are ignored and fatal ones exit the goroutine
+	stopped  bool
+	skipped  bool
+	pending  bool
+	messages []string
+}
+
+func (r *stepRecorder) Log(args ...interface{}) {
+	r.TestingT.Helper()
+
+	if r.active() {
+		r.TestingT.Log(args...)
+	}
+}
+
+func (r *stepRecorder) Logf(format string, args ...interface{}) {
+	r.TestingT.Helper()
+
+	if r.active() {
+		r.TestingT.Logf(format, args...)
+	}
+}
+
+func (r *stepRecorder) Error(args ...interface{}) {
+	r.TestingT.Helper()
+
+	if r.record(args...) {
+		r.TestingT.Error(args...)
+	}
+}
+
+func (r *stepRecorder) Errorf(format string, args ...interface{}) {
+	r.TestingT.Helper()
+
+	if r.recordf(format, args...) {
+		r.TestingT.Errorf(format, args...)
+	}
+}
+
+func (r *stepRecorder) Fatal(args ...interface{}) {
+	r.TestingT.Helper()
+
+	if !r.record(args...) {
+		runtime.Goexit()
+	}
+
+	r.TestingT.Fatal(args...)
+}
+
+func (r *stepRecorder) Fatalf(format string, args ...interface{}) {
+	r.TestingT.Helper()
+
+	if !r.recordf(format, args...) {
+		runtime.Goexit()
+	}
+
+	r.TestingT.Fatalf(format, args...)
+}
+
+func (r *stepRecorder) Skip(args ...interface{}) {
+	r.TestingT.Helper()
+	r.skip()
+	r.TestingT.Skip(args...)
+}
+
+func (r *stepRecorder) Skipf(format string, args ...interface{}) {
+	r.TestingT.Helper()
+	r.skip()
+	r.TestingT.Skipf(format, args...)
+}
+
+func (r *stepRecorder) SkipNow() {
+	r.TestingT.Helper()
+	r.skip()
+	r.TestingT.SkipNow()
+}
+
+// stop records the error and makes the recorder ignore calls of the step function which has timed out
+func (r *stepRecorder) stop(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, err.Error())
+	r.stopped = true
+}
+
+func (r *stepRecorder) active() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !r.stopped
+}
+
+// skip marks the step as skipped or exits the goroutine of the stopped step
+func (r *stepRecorder) skip() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		runtime.Goexit()
+	}
+
+	r.skipped = true
+}
+
+func (r *stepRecorder) markPending() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = !r.stopped
+}
+
+// record records the message unless the recorder is stopped, it returns false in such a case
+func (r *stepRecorder) record(args ...interface{}) bool {
+	return r.recordf("%s", strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+// recordf records the message unless the recorder is stopped, it returns false in such a case
+func (r *stepRecorder) recordf(format string, args ...interface{}) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return false
+	}
+
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+
+	return true
+}
+
+// err returns all the errors reported by the step
+func (r *stepRecorder) err() error {
+	if r == nil {
+		return errors.New("the step failed")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.messages) == 0 {
+		return errors.New("the step failed")
+	}
+
+	return errors.New(strings.Join(r.messages, "\n"))
+}
+
+// reportStepError reports the error returned by the step function. Errors wrapping ErrSkip skip the test,
+// errors wrapping ErrPending mark the step as pending, other errors fail the step.
+func reportStepError(t TestingT, err error) {
+	t.Helper()
+
+	switch {
+	case errors.Is(err, ErrSkip):
+		t.Skip(err.Error())
+	case errors.Is(err, ErrPending):
+		if r, ok := t.(*stepRecorder); ok {
+			r.markPending()
+		}
+
+		t.Fatal(err.Error())
+	default:
+		t.Fatal(formatStepError(err))
+	}
+}
+
+// formatStepError describes the error together with the chain of errors it wraps, so the cause of the failure
+// can be found even if messages of wrapping errors don't contain messages of wrapped ones
+func formatStepError(err error) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%+v", err)
+
+	if errors.Unwrap(err) == nil {
+		return sb.String()
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		fmt.Fprintf(&sb, "\n\t%T: %s", e, e)
+	}
+
+	return sb.String()
+}
@@ -1,7 +1,17 @@
 package gobdd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
 	"testing"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateStepFunc(t *testing.T) {
@@ -20,6 +30,71 @@ func TestValidateStepFunc(t *testing.T) {
 	}
 }
 
+func TestValidateStepFunc_UnsupportedArguments(t *testing.T) {
+	testCases := map[string]struct {
+		f        interface{}
+		expected string
+	}{
+		"map": {
+			f:        func(StepTest, Context, map[int]string, string) {},
+			expected: "the map argument type map[int]string is not supported",
+		},
+		"struct": {
+			f:        func(StepTest, Context, struct{}, string) {},
+			expected: "the struct argument type struct {} is not supported",
+		},
+		"slice": {
+			f:        func(StepTest, Context, []int, string) {},
+			expected: "the slice argument type []int is not supported",
+		},
+		"interface": {f: func(StepTest, Context, error, string) {}, expected: "the type error is not supported"},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			err := validateStepFunc(testCase.f)
+			if err == nil || err.Error() != "the argument 3 cannot be converted: "+testCase.expected {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateStepFunc_DocString(t *testing.T) {
+	testCases := map[string]interface{}{
+		"struct":             func(StepTest, Context, struct{ Name string }) {},
+		"pointer to struct":  func(StepTest, Context, *struct{ Name string }) {},
+		"map":                func(StepTest, Context, map[string]interface{}) {},
+		"slice":              func(StepTest, Context, []int) {},
+		"empty interface":    func(StepTest, Context, interface{}) {},
+		"doc string":         func(StepTest, Context, DocString) {},
+		"raw json":           func(StepTest, Context, json.RawMessage) {},
+		"with the parameter": func(StepTest, Context, int, map[int]string) {},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			if err := validateStepFunc(testCase); err != nil {
+				t.Errorf("the test should NOT fail for the function: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateStepFunc_SupportedArguments(t *testing.T) {
+	f := func(StepTest, Context, int8, uint64, bool, time.Duration, *float32, myString, time.Time, *big.Int,
+		json.RawMessage, []byte, msgs.DataTable) {
+	}
+
+	if err := validateStepFunc(f); err != nil {
+		t.Errorf("the test should NOT fail for the function: %s", err)
+	}
+}
+
 func TestValidateStepFunc_ValidFunction(t *testing.T) {
 	if err := validateStepFunc(func(StepTest, Context) {}); err != nil {
 		t.Errorf("the test should NOT fail for the function: %s", err)
@@ -36,3 +111,71 @@ func TestValidateStepFunc_ReturnContext(t *testing.T) {
 func ValidateStepFunc(f interface{}) error {
 	return validateStepFunc(f)
 }
+
+func TestReportStepError(t *testing.T) {
+	testCases := map[string]struct {
+		err             error
+		expectedFatal   []string
+		expectedSkip    []string
+		expectedPending bool
+	}{
+		"error": {
+			err:           errors.New("the request failed"),
+			expectedFatal: []string{"the request failed"},
+		},
+		"wrapped error": {
+			err: fmt.Errorf("cannot create the user: %w", &os.PathError{Op: "open", Path: "users.json",
+				Err: os.ErrNotExist}),
+			expectedFatal: []string{"cannot create the user: open users.json: file does not exist" +
+				"\n\t*fmt.wrapError: cannot create the user: open users.json: file does not exist" +
+				"\n\t*fs.PathError: open users.json: file does not exist" +
+				"\n\t*errors.errorString: file does not exist"},
+		},
+		"pending": {
+			err:             fmt.Errorf("sending emails: %w", ErrPending),
+			expectedFatal:   []string{"sending emails: the step is pending"},
+			expectedPending: true,
+		},
+		"skip": {
+			err:          fmt.Errorf("the database is unavailable: %w", ErrSkip),
+			expectedSkip: []string{"the database is unavailable: the step is skipped"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			tester := &mockTester{}
+			recorder := &stepRecorder{TestingT: tester}
+
+			reportStepError(recorder, testCase.err)
+
+			require.Equal(t, testCase.expectedFatal, tester.fatalMessages)
+			require.Equal(t, testCase.expectedSkip, tester.skipMessages)
+			require.Equal(t, testCase.expectedPending, recorder.pending)
+			require.Equal(t, testCase.expectedSkip != nil, recorder.skipped)
+		})
+	}
+}
+
+func TestStepDef_RunReturningError(t *testing.T) {
+	testCases := map[string]interface{}{
+		"StepTest and Context": func(StepTest, Context) error { return ErrPending },
+		"context.Context":      func(context.Context) error { return ErrPending },
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, validateStepFunc(testCase))
+
+			recorder := &stepRecorder{TestingT: &mockTester{}}
+
+			require.NoError(t, (&stepDef{f: testCase}).run(NewContext(), recorder, nil, 0))
+			require.True(t, recorder.pending)
+			require.EqualError(t, recorder.err(), "the step is pending")
+		})
+	}
+}
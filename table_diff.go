@@ -0,0 +1,311 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// TableDiffOptions holds all the information about how tables should be compared
+type TableDiffOptions struct {
+	unorderedRows  bool
+	surplusRows    bool
+	surplusColumns bool
+}
+
+// WithUnorderedRows makes DiffTable ignore the order of rows. The header, which is the first row
+// of both tables, is always compared with the header.
+func WithUnorderedRows() func(*TableDiffOptions) {
+	return func(options *TableDiffOptions) {
+		options.unorderedRows = true
+	}
+}
+
+// WithSurplusRows makes DiffTable accept actual rows which are not present in the expected table,
+// so the expected table has to be a subset of the actual one
+func WithSurplusRows() func(*TableDiffOptions) {
+	return func(options *TableDiffOptions) {
+		options.surplusRows = true
+	}
+}
+
+// WithSurplusColumns makes DiffTable accept actual columns which are not present in the expected table.
+// Columns are matched by the header, which is the first row of both tables.
+func WithSurplusColumns() func(*TableDiffOptions) {
+	return func(options *TableDiffOptions) {
+		options.surplusColumns = true
+	}
+}
+
+// DiffTable compares the expected data table with actual rows. When they're different, the returned error
+// contains both tables merged in the Gherkin format: missing rows are marked with "-" and surplus ones with "+".
+//
+//	suite.AddStep(`the users are:`, func(t gobdd.StepTest, ctx gobdd.Context, expected msgs.DataTable) {
+//		if err := gobdd.DiffTable(expected, actualUsers(ctx), gobdd.WithUnorderedRows()); err != nil {
+//			t.Fatal(err)
+//		}
+//	})
+func DiffTable(expected msgs.DataTable, actual [][]string, optionClosures ...func(*TableDiffOptions)) error {
+	options := TableDiffOptions{}
+	for _, f := range optionClosures {
+		f(&options)
+	}
+
+	expectedRows := tableRows(expected)
+
+	var diff []tableDiffRow
+
+	if options.surplusColumns && len(expectedRows) > 0 && len(actual) > 0 {
+		var missingColumns []string
+
+		expectedRows, actual, missingColumns = projectColumns(expectedRows, actual)
+		for _, column := range missingColumns {
+			diff = append(diff, tableDiffRow{kind: tableDiffMissingColumn, cells: []string{column}})
+		}
+	}
+
+	if options.unorderedRows {
+		diff = append(diff, unorderedTableDiff(expectedRows, actual)...)
+	} else {
+		diff = append(diff, orderedTableDiff(expectedRows, actual)...)
+	}
+
+	for _, row := range diff {
+		if row.kind == tableDiffMissing || row.kind == tableDiffMissingColumn ||
+			row.kind == tableDiffSurplus && !options.surplusRows {
+			return errors.New("the tables are different:\n" + formatTableDiff(diff))
+		}
+	}
+
+	return nil
+}
+
+type tableDiffKind int
+
+const (
+	tableDiffEqual tableDiffKind = iota
+	tableDiffMissing
+	tableDiffSurplus
+	tableDiffMissingColumn
+)
+
+type tableDiffRow struct {
+	kind  tableDiffKind
+	cells []string
+}
+
+// tableRows returns values of cells of the data table
+func tableRows(table msgs.DataTable) [][]string {
+	rows := make([][]string, 0, len(table.Rows))
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			cells = append(cells, cell.Value)
+		}
+
+		rows = append(rows, cells)
+	}
+
+	return rows
+}
+
+// projectColumns leaves only columns of the expected header which are present in the actual header, in the order
+// of the expected header, in both tables. Expected columns missing from the actual header are returned,
+// so they're reported on their own and the rest of columns is still compared.
+func projectColumns(expected, actual [][]string) (projectedExpected, projectedActual [][]string, missing []string) {
+	expectedIndexes := make([]int, 0, len(expected[0]))
+	actualIndexes := make([]int, 0, len(expected[0]))
+
+	for i, column := range expected[0] {
+		index := -1
+
+		for j, actualColumn := range actual[0] {
+			if actualColumn == column {
+				index = j
+
+				break
+			}
+		}
+
+		if index < 0 {
+			missing = append(missing, column)
+
+			continue
+		}
+
+		expectedIndexes = append(expectedIndexes, i)
+		actualIndexes = append(actualIndexes, index)
+	}
+
+	return selectColumns(expected, expectedIndexes), selectColumns(actual, actualIndexes), missing
+}
+
+// selectColumns returns rows with cells of given columns only
+func selectColumns(rows [][]string, indexes []int) [][]string {
+	selected := make([][]string, 0, len(rows))
+
+	for _, row := range rows {
+		cells := make([]string, 0, len(indexes))
+
+		for _, i := range indexes {
+			if i < len(row) {
+				cells = append(cells, row[i])
+			}
+		}
+
+		selected = append(selected, cells)
+	}
+
+	return selected
+}
+
+// orderedTableDiff finds the longest common subsequence of rows, the rest of rows are missing or surplus
+func orderedTableDiff(expected, actual [][]string) []tableDiffRow {
+	// lengths[i][j] is the length of the longest common subsequence of expected[i:] and actual[j:]
+	lengths := make([][]int, len(expected)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(actual)+1)
+	}
+
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			switch {
+			case equalRows(expected[i], actual[j]):
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	diff := make([]tableDiffRow, 0, len(expected)+len(actual))
+	i, j := 0, 0
+
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && equalRows(expected[i], actual[j]):
+			diff = append(diff, tableDiffRow{kind: tableDiffEqual, cells: expected[i]})
+			i++
+			j++
+		case i < len(expected) && (j == len(actual) || lengths[i+1][j] >= lengths[i][j+1]):
+			diff = append(diff, tableDiffRow{kind: tableDiffMissing, cells: expected[i]})
+			i++
+		default:
+			diff = append(diff, tableDiffRow{kind: tableDiffSurplus, cells: actual[j]})
+			j++
+		}
+	}
+
+	return diff
+}
+
+// unorderedTableDiff compares headers (the first rows) of both tables and then matches every expected row
+// with an equal actual row, surplus rows are listed at the end
+func unorderedTableDiff(expected, actual [][]string) []tableDiffRow {
+	diff := make([]tableDiffRow, 0, len(expected)+len(actual))
+
+	if len(expected) > 0 && len(actual) > 0 {
+		if equalRows(expected[0], actual[0]) {
+			diff = append(diff, tableDiffRow{kind: tableDiffEqual, cells: expected[0]})
+		} else {
+			diff = append(diff,
+				tableDiffRow{kind: tableDiffMissing, cells: expected[0]},
+				tableDiffRow{kind: tableDiffSurplus, cells: actual[0]},
+			)
+		}
+
+		expected, actual = expected[1:], actual[1:]
+	}
+
+	matched := make([]bool, len(actual))
+
+	for _, row := range expected {
+		kind := tableDiffMissing
+
+		for j := range actual {
+			if !matched[j] && equalRows(row, actual[j]) {
+				matched[j] = true
+				kind = tableDiffEqual
+
+				break
+			}
+		}
+
+		diff = append(diff, tableDiffRow{kind: kind, cells: row})
+	}
+
+	for j, row := range actual {
+		if !matched[j] {
+			diff = append(diff, tableDiffRow{kind: tableDiffSurplus, cells: row})
+		}
+	}
+
+	return diff
+}
+
+func equalRows(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatTableDiff writes rows as a Gherkin table with aligned columns, prefixed with the kind of the difference
+func formatTableDiff(diff []tableDiffRow) string {
+	widths := []int{}
+
+	for _, row := range diff {
+		if row.kind == tableDiffMissingColumn {
+			continue
+		}
+
+		for i, cell := range row.cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if l := utf8.RuneCountInString(cell); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+
+	prefixes := map[tableDiffKind]string{
+		tableDiffEqual:   "      ",
+		tableDiffMissing: "    - ",
+		tableDiffSurplus: "    + ",
+	}
+
+	var sb strings.Builder
+
+	for _, row := range diff {
+		if row.kind == tableDiffMissingColumn {
+			fmt.Fprintf(&sb, "    - column %q\n", row.cells[0])
+
+			continue
+		}
+
+		sb.WriteString(prefixes[row.kind] + "|")
+
+		for i, cell := range row.cells {
+			fmt.Fprintf(&sb, " %s%s |", cell, strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
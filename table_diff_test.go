@@ -0,0 +1,144 @@
+package gobdd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTable(t *testing.T) {
+	expected := newDataTable(
+		[]string{"name", "age"},
+		[]string{"alice", "30"},
+		[]string{"bob", "25"},
+	)
+
+	testCases := map[string]struct {
+		actual   [][]string
+		options  []func(*TableDiffOptions)
+		expected string
+	}{
+		"equal": {
+			actual: [][]string{{"name", "age"}, {"alice", "30"}, {"bob", "25"}},
+		},
+		"different cell": {
+			actual: [][]string{{"name", "age"}, {"alice", "30"}, {"bob", "26"}},
+			expected: "" +
+				"      | name  | age |\n" +
+				"      | alice | 30  |\n" +
+				"    - | bob   | 25  |\n" +
+				"    + | bob   | 26  |\n",
+		},
+		"missing row": {
+			actual: [][]string{{"name", "age"}, {"bob", "25"}},
+			expected: "" +
+				"      | name  | age |\n" +
+				"    - | alice | 30  |\n" +
+				"      | bob   | 25  |\n",
+		},
+		"surplus row": {
+			actual: [][]string{{"name", "age"}, {"alice", "30"}, {"carol", "41"}, {"bob", "25"}},
+			expected: "" +
+				"      | name  | age |\n" +
+				"      | alice | 30  |\n" +
+				"    + | carol | 41  |\n" +
+				"      | bob   | 25  |\n",
+		},
+		"different order": {
+			actual: [][]string{{"name", "age"}, {"bob", "25"}, {"alice", "30"}},
+			expected: "" +
+				"      | name  | age |\n" +
+				"    - | alice | 30  |\n" +
+				"      | bob   | 25  |\n" +
+				"    + | alice | 30  |\n",
+		},
+		"unordered": {
+			actual:  [][]string{{"name", "age"}, {"bob", "25"}, {"alice", "30"}},
+			options: []func(*TableDiffOptions){WithUnorderedRows()},
+		},
+		"unordered with a missing row": {
+			actual:  [][]string{{"name", "age"}, {"bob", "25"}, {"carol", "41"}},
+			options: []func(*TableDiffOptions){WithUnorderedRows()},
+			expected: "" +
+				"      | name  | age |\n" +
+				"    - | alice | 30  |\n" +
+				"      | bob   | 25  |\n" +
+				"    + | carol | 41  |\n",
+		},
+		"subset": {
+			actual:  [][]string{{"name", "age"}, {"alice", "30"}, {"carol", "41"}, {"bob", "25"}},
+			options: []func(*TableDiffOptions){WithSurplusRows()},
+		},
+		"unordered subset": {
+			actual:  [][]string{{"name", "age"}, {"carol", "41"}, {"bob", "25"}, {"alice", "30"}},
+			options: []func(*TableDiffOptions){WithUnorderedRows(), WithSurplusRows()},
+		},
+		"subset with a missing row": {
+			actual:  [][]string{{"name", "age"}, {"carol", "41"}, {"bob", "25"}},
+			options: []func(*TableDiffOptions){WithSurplusRows()},
+			expected: "" +
+				"      | name  | age |\n" +
+				"    - | alice | 30  |\n" +
+				"    + | carol | 41  |\n" +
+				"      | bob   | 25  |\n",
+		},
+		"surplus column": {
+			actual:  [][]string{{"age", "email", "name"}, {"30", "alice@example.com", "alice"}, {"25", "", "bob"}},
+			options: []func(*TableDiffOptions){WithSurplusColumns()},
+		},
+		"surplus column without the option": {
+			actual: [][]string{{"name", "age", "email"}, {"alice", "30", "a@b.c"}, {"bob", "25", ""}},
+			expected: "" +
+				"    - | name  | age |\n" +
+				"    - | alice | 30  |\n" +
+				"    - | bob   | 25  |\n" +
+				"    + | name  | age | email |\n" +
+				"    + | alice | 30  | a@b.c |\n" +
+				"    + | bob   | 25  |       |\n",
+		},
+		"missing column": {
+			actual:  [][]string{{"name"}, {"alice"}, {"bob"}},
+			options: []func(*TableDiffOptions){WithSurplusColumns()},
+			expected: "" +
+				"    - column \"age\"\n" +
+				"      | name  |\n" +
+				"      | alice |\n" +
+				"      | bob   |\n",
+		},
+		"missing column and a different cell": {
+			actual:  [][]string{{"name"}, {"alice"}, {"carol"}},
+			options: []func(*TableDiffOptions){WithSurplusColumns()},
+			expected: "" +
+				"    - column \"age\"\n" +
+				"      | name  |\n" +
+				"      | alice |\n" +
+				"    - | bob   |\n" +
+				"    + | carol |\n",
+		},
+		"unordered with a different header": {
+			actual:  [][]string{{"age", "name"}, {"25", "bob"}, {"alice", "30"}},
+			options: []func(*TableDiffOptions){WithUnorderedRows()},
+			expected: "" +
+				"    - | name  | age  |\n" +
+				"    + | age   | name |\n" +
+				"      | alice | 30   |\n" +
+				"    - | bob   | 25   |\n" +
+				"    + | 25    | bob  |\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			err := DiffTable(expected, testCase.actual, testCase.options...)
+			if testCase.expected == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.EqualError(t, err, "the tables are different:\n"+testCase.expected)
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package gobdd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// tagExpression is a compiled Cucumber tag expression.
+// See https://cucumber.io/docs/cucumber/api/#tag-expressions
+type tagExpression interface {
+	// evaluate tells whether the expression matches the given list of tag names
+	evaluate(tags []string) bool
+	String() string
+}
+
+type tagLiteral struct {
+	value string
+}
+
+func (e tagLiteral) evaluate(tags []string) bool {
+	return contains(tags, e.value)
+}
+
+func (e tagLiteral) String() string {
+	var sb strings.Builder
+
+	for _, r := range e.value {
+		if r == '\\' || r == '(' || r == ')' || unicode.IsSpace(r) {
+			sb.WriteRune('\\')
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+type tagOr struct {
+	left, right tagExpression
+}
+
+func (e tagOr) evaluate(tags []string) bool {
+	return e.left.evaluate(tags) || e.right.evaluate(tags)
+}
+
+func (e tagOr) String() string {
+	return fmt.Sprintf("( %s or %s )", e.left, e.right)
+}
+
+type tagAnd struct {
+	left, right tagExpression
+}
+
+func (e tagAnd) evaluate(tags []string) bool {
+	return e.left.evaluate(tags) && e.right.evaluate(tags)
+}
+
+func (e tagAnd) String() string {
+	return fmt.Sprintf("( %s and %s )", e.left, e.right)
+}
+
+type tagNot struct {
+	expr tagExpression
+}
+
+func (e tagNot) evaluate(tags []string) bool {
+	return !e.expr.evaluate(tags)
+}
+
+func (e tagNot) String() string {
+	switch e.expr.(type) {
+	case tagAnd, tagOr:
+		// the nested expression is already wrapped in parentheses
+		return "not " + e.expr.String()
+	default:
+		return fmt.Sprintf("not ( %s )", e.expr)
+	}
+}
+
+type tagTrue struct{}
+
+func (tagTrue) evaluate([]string) bool {
+	return true
+}
+
+func (tagTrue) String() string {
+	return "true"
+}
+
+const (
+	tagTokenOr     = "or"
+	tagTokenAnd    = "and"
+	tagTokenNot    = "not"
+	tagTokenLParen = "("
+	tagTokenRParen = ")"
+)
+
+var tagOperatorPrecedence = map[string]int{
+	tagTokenLParen: -2,
+	tagTokenRParen: -1,
+	tagTokenOr:     0,
+	tagTokenAnd:    1,
+	tagTokenNot:    2,
+}
+
+type tagToken struct {
+	value   string
+	literal bool
+}
+
+func (t tagToken) isOperator() bool {
+	if t.literal {
+		return false
+	}
+
+	_, ok := tagOperatorPrecedence[t.value]
+
+	return ok && t.value != tagTokenLParen && t.value != tagTokenRParen
+}
+
+func (t tagToken) is(value string) bool {
+	return !t.literal && t.value == value
+}
+
+// parseTagExpression compiles an infix tag expression like `@smoke and not (@slow or @wip)`.
+// The empty expression matches everything.
+func parseTagExpression(expr string) (tagExpression, error) {
+	tokens, err := tokenizeTagExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return tagTrue{}, nil
+	}
+
+	p := tagExpressionParser{expr: expr}
+
+	expectOperand := true
+
+	for _, token := range tokens {
+		switch {
+		case token.is(tagTokenNot):
+			if err := p.expect(expectOperand, token); err != nil {
+				return nil, err
+			}
+
+			p.operators = append(p.operators, token)
+		case token.is(tagTokenAnd), token.is(tagTokenOr):
+			if err := p.expect(expectOperand, token); err != nil {
+				return nil, err
+			}
+
+			for len(p.operators) > 0 && p.peek().isOperator() &&
+				tagOperatorPrecedence[token.value] <= tagOperatorPrecedence[p.peek().value] {
+				if err := p.reduce(); err != nil {
+					return nil, err
+				}
+			}
+
+			p.operators = append(p.operators, token)
+			expectOperand = true
+		case token.is(tagTokenLParen):
+			if err := p.expect(expectOperand, token); err != nil {
+				return nil, err
+			}
+
+			p.operators = append(p.operators, token)
+		case token.is(tagTokenRParen):
+			if err := p.expect(expectOperand, token); err != nil {
+				return nil, err
+			}
+
+			for len(p.operators) > 0 && !p.peek().is(tagTokenLParen) {
+				if err := p.reduce(); err != nil {
+					return nil, err
+				}
+			}
+
+			if len(p.operators) == 0 {
+				return nil, p.syntaxError("unmatched )")
+			}
+
+			p.operators = p.operators[:len(p.operators)-1]
+		default:
+			if err := p.expect(expectOperand, token); err != nil {
+				return nil, err
+			}
+
+			p.operands = append(p.operands, tagLiteral{value: token.value})
+			expectOperand = false
+		}
+	}
+
+	for len(p.operators) > 0 {
+		if p.peek().is(tagTokenLParen) {
+			return nil, p.syntaxError("unmatched (")
+		}
+
+		if err := p.reduce(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(p.operands) != 1 {
+		return nil, p.syntaxError("expected operator")
+	}
+
+	return p.operands[0], nil
+}
+
+type tagExpressionParser struct {
+	expr      string
+	operators []tagToken
+	operands  []tagExpression
+}
+
+func (p *tagExpressionParser) peek() tagToken {
+	return p.operators[len(p.operators)-1]
+}
+
+func (p *tagExpressionParser) expect(operandExpected bool, token tagToken) error {
+	if operandExpected == (token.literal || token.is(tagTokenNot) || token.is(tagTokenLParen)) {
+		return nil
+	}
+
+	if operandExpected {
+		return p.syntaxError(fmt.Sprintf("expected operand but got %q", token.value))
+	}
+
+	return p.syntaxError(fmt.Sprintf("expected operator but got %q", token.value))
+}
+
+// reduce pops the top operator and replaces its operands with the combined expression
+func (p *tagExpressionParser) reduce() error {
+	operator := p.peek()
+	p.operators = p.operators[:len(p.operators)-1]
+
+	if operator.is(tagTokenNot) {
+		if len(p.operands) < 1 {
+			return p.syntaxError("not: expected operand")
+		}
+
+		expr := p.operands[len(p.operands)-1]
+		p.operands[len(p.operands)-1] = tagNot{expr: expr}
+
+		return nil
+	}
+
+	if len(p.operands) < 2 { // nolint:mnd
+		return p.syntaxError(fmt.Sprintf("%s: expected two operands", operator.value))
+	}
+
+	left, right := p.operands[len(p.operands)-2], p.operands[len(p.operands)-1]
+	p.operands = p.operands[:len(p.operands)-2]
+
+	if operator.is(tagTokenAnd) {
+		p.operands = append(p.operands, tagAnd{left: left, right: right})
+	} else {
+		p.operands = append(p.operands, tagOr{left: left, right: right})
+	}
+
+	return nil
+}
+
+func (p *tagExpressionParser) syntaxError(msg string) error {
+	return fmt.Errorf("tag expression %q could not be parsed because of syntax error: %s", p.expr, msg)
+}
+
+func tokenizeTagExpression(expr string) ([]tagToken, error) {
+	var (
+		tokens  []tagToken
+		current strings.Builder
+		escaped bool
+		// literal is true when the current token contains escaped characters,
+		// so an escaped `\(` is never mistaken for a parenthesis
+		literal bool
+	)
+
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+
+		value := current.String()
+		_, isOperator := tagOperatorPrecedence[value]
+		tokens = append(tokens, tagToken{value: value, literal: literal || !isOperator})
+		current.Reset()
+		literal = false
+	}
+
+	for _, r := range expr {
+		switch {
+		case escaped:
+			if r != '(' && r != ')' && r != '\\' && !unicode.IsSpace(r) {
+				return nil, fmt.Errorf(
+					"tag expression %q could not be parsed because of syntax error: illegal escape before %q",
+					expr, r)
+			}
+
+			current.WriteRune(r)
+			escaped = false
+			literal = true
+		case r == '\\':
+			escaped = true
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, tagToken{value: string(r)})
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf(
+			"tag expression %q could not be parsed because of syntax error: unfinished escape sequence", expr)
+	}
+
+	flush()
+
+	return tokens, nil
+}
+
+// tagNames returns names of given tags (like @smoke) in the order they appear
+func tagNames(tags []*msgs.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
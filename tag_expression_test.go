@@ -0,0 +1,115 @@
+package gobdd
+
+import (
+	"testing"
+)
+
+func TestParseTagExpression(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		tags     []string
+		expected bool
+	}{
+		{expr: "", tags: nil, expected: true},
+		{expr: "@a", tags: []string{"@a"}, expected: true},
+		{expr: "@a", tags: []string{"@b"}, expected: false},
+		{expr: "not @a", tags: []string{"@a"}, expected: false},
+		{expr: "not @a", tags: nil, expected: true},
+		{expr: "@a and @b", tags: []string{"@a"}, expected: false},
+		{expr: "@a and @b", tags: []string{"@a", "@b"}, expected: true},
+		{expr: "@a or @b", tags: []string{"@b"}, expected: true},
+		{expr: "@a or @b and @c", tags: []string{"@a"}, expected: true},
+		{expr: "(@a or @b) and @c", tags: []string{"@a"}, expected: false},
+		{expr: "@smoke and not @slow", tags: []string{"@smoke", "@slow"}, expected: false},
+		{expr: "(@api or @ui) and not @wip", tags: []string{"@ui"}, expected: true},
+		{expr: "(@api or @ui) and not @wip", tags: []string{"@api", "@wip"}, expected: false},
+		{expr: "not not @a", tags: []string{"@a"}, expected: true},
+		{expr: `@a\(1\)`, tags: []string{"@a(1)"}, expected: true},
+		{expr: `@a\ b`, tags: []string{"@a b"}, expected: true},
+		{expr: `@a\\b`, tags: []string{`@a\b`}, expected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expr, func(t *testing.T) {
+			expr, err := parseTagExpression(testCase.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := expr.evaluate(testCase.tags); got != testCase.expected {
+				t.Errorf("expected %t for tags %v but %t got", testCase.expected, testCase.tags, got)
+			}
+		})
+	}
+}
+
+func TestParseTagExpression_String(t *testing.T) {
+	testCases := map[string]string{
+		"@a and @b or not @c":       "( ( @a and @b ) or not ( @c ) )",
+		"not (@a or @b)":            "not ( @a or @b )",
+		`@a\(1\) and @b`:            `( @a\(1\) and @b )`,
+		"  @a   or   @b  and  @c  ": "( @a or ( @b and @c ) )",
+	}
+
+	for expr, expected := range testCases {
+		t.Run(expr, func(t *testing.T) {
+			parsed, err := parseTagExpression(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if parsed.String() != expected {
+				t.Errorf("expected %s but %s got", expected, parsed.String())
+			}
+		})
+	}
+}
+
+func TestParseTagExpression_Errors(t *testing.T) {
+	testCases := []string{
+		"@a @b",
+		"@a and",
+		"and @a",
+		"not",
+		"(@a",
+		"@a)",
+		"()",
+		`@a\b`,
+		`@a\`,
+	}
+
+	for _, expr := range testCases {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := parseTagExpression(expr); err == nil {
+				t.Errorf("the expression %q should not be parsed", expr)
+			}
+		})
+	}
+}
+
+func TestTagExpression(t *testing.T) {
+	c := 0
+	suite := NewSuite(t, WithFeaturesPath("features/tag_expression.feature"),
+		WithTagExpression("@api and @smoke and not (@slow or @wip)"))
+	suite.AddStep(`the test should pass`, func(_ StepTest, _ Context) {
+		c++
+	})
+	suite.AddStep(`fail the test`, fail)
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+
+	if c != 2 {
+		t.Errorf("expected to run %d scenarios but %d got", 2, c)
+	}
+}
+
+func TestTagExpression_Invalid(t *testing.T) {
+	tester := &mockTester{}
+	NewSuite(tester, WithTagExpression("@a and"))
+
+	if tester.fatalCalled != 1 {
+		t.Errorf("expected the suite to fail on invalid tag expression")
+	}
+}
@@ -0,0 +1,158 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+)
+
+// timeoutTagPrefix starts tags configuring the timeout of the scenario, like @timeout:30s
+const timeoutTagPrefix = "@timeout:"
+
+// WithDefaultStepTimeout configures how long every step can be executed, unless the step definition has its own
+// timeout configured with WithStepTimeout. A step exceeding the timeout fails with the stack of the step function
+// and the context.Context passed to the step is cancelled. There's no timeout by default.
+func WithDefaultStepTimeout(timeout time.Duration) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.stepTimeout = timeout
+	}
+}
+
+// WithScenarioTimeout configures how long every scenario can be executed, unless the scenario is tagged with
+// a tag like @timeout:30s. The tag can be added to the feature, the rule or the Examples block as well.
+// When the scenario exceeds the timeout, the executed step fails. There's no timeout by default.
+func WithScenarioTimeout(timeout time.Duration) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.scenarioTimeout = timeout
+	}
+}
+
+// WithStepTimeout configures how long the step can be executed, it overrides WithDefaultStepTimeout
+func WithStepTimeout(timeout time.Duration) func(*StepOptions) {
+	return func(options *StepOptions) {
+		options.timeout = timeout
+	}
+}
+
+// scenarioTimeout returns the timeout configured by the last @timeout tag or WithScenarioTimeout
+func (s *Suite) scenarioTimeout(tags []*msgs.Tag) (time.Duration, error) {
+	timeout := s.options.scenarioTimeout
+
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag.Name, timeoutTagPrefix) {
+			continue
+		}
+
+		d, err := time.ParseDuration(strings.TrimPrefix(tag.Name, timeoutTagPrefix))
+		if err != nil {
+			return 0, fmt.Errorf("the tag %s is incorrect: %w", tag.Name, err)
+		}
+
+		timeout = d
+	}
+
+	return timeout, nil
+}
+
+// stepTimeout returns how long the step can be executed, zero means there's no limit. The timeout of the step
+// is shortened to the time left to the scenario's deadline, in such a case scenario is true.
+func (s *Suite) stepTimeout(tc *testCase, step *testStep) (timeout time.Duration, scenario bool) {
+	timeout = s.options.stepTimeout
+	if step.def.timeout > 0 {
+		timeout = step.def.timeout
+	}
+
+	if tc.timeout > 0 {
+		if left := time.Until(tc.started.Add(tc.timeout)); timeout == 0 || left < timeout {
+			return left, true
+		}
+	}
+
+	return timeout, false
+}
+
+// errStepTimeout describes the step which exceeded the timeout of the step or the scenario
+func errStepTimeout(tc *testCase, step *testStep, timeout time.Duration, scenario bool, stack string) error {
+	var sb strings.Builder
+
+	if scenario {
+		fmt.Fprintf(&sb, "the scenario %s has timed out after %s while executing the step %s%s (%s:%d)",
+			tc.name(), tc.timeout, step.step.Keyword, step.step.Text, tc.uri, step.step.Location.Line)
+	} else {
+		fmt.Fprintf(&sb, "the step %s%s (%s:%d) has timed out after %s",
+			step.step.Keyword, step.step.Text, tc.uri, step.step.Location.Line, timeout)
+	}
+
+	if stack != "" {
+		fmt.Fprintf(&sb, "\n\n%s", stack)
+	}
+
+	return errors.New(sb.String())
+}
+
+// timeoutError is returned when the step function doesn't finish before the timeout
+type timeoutError struct {
+	timeout time.Duration
+	// stack is the stack of the goroutine executing the step function when the timeout has been exceeded
+	stack string
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("the step has timed out after %s", e.timeout)
+}
+
+// callWithTimeout calls f in a new goroutine and waits until it finishes or the timeout is exceeded.
+// In the latter case, the goroutine is left running and its stack is returned in the timeoutError.
+func callWithTimeout(timeout time.Duration, f func()) error {
+	done := make(chan struct{})
+	id := make(chan string, 1)
+
+	go func() {
+		defer close(done)
+
+		id <- goroutineID()
+		f()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return &timeoutError{timeout: timeout, stack: goroutineStack(<-id)}
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, taken from the header of its stack
+func goroutineID() string {
+	buf := make([]byte, 64) // nolint:mnd
+	buf = buf[:runtime.Stack(buf, false)]
+
+	// the stack starts with "goroutine 42 [running]:"
+	fields := strings.Fields(string(buf))
+	if len(fields) < 2 { // nolint:mnd
+		return ""
+	}
+
+	return fields[1]
+}
+
+// goroutineStack returns the stack of the goroutine with the given ID
+func goroutineStack(id string) string {
+	buf := make([]byte, 1<<20) // nolint:mnd
+	buf = buf[:runtime.Stack(buf, true)]
+
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if strings.HasPrefix(stack, "goroutine "+id+" ") {
+			return stack
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,169 @@
+package gobdd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	msgs "github.com/cucumber/messages/go/v28"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeouts(t *testing.T) {
+	var deadlines []time.Duration
+
+	suite := NewSuite(t, WithFeaturesPath("features/timeout.feature"), WithDefaultStepTimeout(time.Hour))
+	suite.AddStep(`the step has a deadline`, func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return errors.New("the step has no deadline")
+		}
+
+		deadlines = append(deadlines, time.Until(deadline))
+
+		return nil
+	})
+	suite.AddStep(`the step takes {int}ms`, func(ctx context.Context, ms int) {
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+	}, WithStepTimeout(time.Second))
+
+	suite.Run()
+
+	require.Len(t, deadlines, 3)
+	require.True(t, deadlines[0] > 50*time.Second && deadlines[0] <= time.Minute, deadlines[0])
+	require.True(t, deadlines[1] < deadlines[0], deadlines[1])
+	require.True(t, deadlines[2] > time.Minute && deadlines[2] <= 2*time.Minute, deadlines[2])
+}
+
+func TestStepDef_RunWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	stepCtx := make(chan context.Context, 1)
+
+	def := stepDef{f: func(ctx context.Context) {
+		stepCtx <- ctx
+		<-release
+	}}
+
+	err := def.run(NewContext(), &mockTester{}, nil, 10*time.Millisecond)
+
+	var timeoutErr *timeoutError
+
+	require.ErrorAs(t, err, &timeoutErr)
+	require.EqualError(t, err, "the step has timed out after 10ms")
+	require.Contains(t, timeoutErr.stack, "TestStepDef_RunWithTimeout")
+	require.Error(t, (<-stepCtx).Err())
+}
+
+func TestStepDef_RunWithTimeout_Finished(t *testing.T) {
+	def := stepDef{f: func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return errors.New("the step has no deadline")
+		}
+
+		return nil
+	}}
+	tester := &mockTester{}
+
+	require.NoError(t, def.run(NewContext(), tester, nil, time.Second))
+	require.Empty(t, tester.fatalMessages)
+}
+
+func TestStepDef_RunWithTimeout_ContextCopy(t *testing.T) {
+	release := make(chan struct{})
+	finished := make(chan struct{})
+
+	def := stepDef{f: func(t StepTest, ctx Context) {
+		defer close(finished)
+
+		<-release
+		ctx.Set("value", "set after the timeout")
+	}}
+	ctx := NewContext()
+
+	err := def.run(ctx, &mockTester{}, nil, 10*time.Millisecond)
+	require.Error(t, err)
+
+	ctx.Set("value", "set by the following step")
+	close(release)
+	<-finished
+
+	value, err := ctx.Get("value")
+	require.NoError(t, err)
+	require.Equal(t, "set by the following step", value)
+}
+
+func TestStepDef_RunWithTimeout_ContextValues(t *testing.T) {
+	def := stepDef{f: func(t StepTest, ctx Context) {
+		ctx.Set("value", "set by the step")
+	}}
+	ctx := NewContext()
+
+	require.NoError(t, def.run(ctx, &mockTester{}, nil, time.Second))
+
+	value, err := ctx.Get("value")
+	require.NoError(t, err)
+	require.Equal(t, "set by the step", value)
+}
+
+func TestStepRecorder_Stopped(t *testing.T) {
+	tester := &mockTester{}
+	recorder := &stepRecorder{TestingT: tester}
+
+	recorder.Error("the first error")
+	recorder.stop(errors.New("the step has timed out"))
+	recorder.Error("the second error")
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		recorder.Fatal("the fatal error")
+		t.Error("the goroutine of the stopped step should exit")
+	}()
+	<-done
+
+	require.Equal(t, []string{"the first error"}, tester.errors)
+	require.Empty(t, tester.fatalMessages)
+	require.EqualError(t, recorder.err(), "the first error\nthe step has timed out")
+}
+
+func TestSuite_ScenarioTimeout(t *testing.T) {
+	tag := func(name string) *msgs.Tag {
+		return &msgs.Tag{Name: name}
+	}
+
+	suite := NewSuite(t, WithScenarioTimeout(time.Minute))
+
+	timeout, err := suite.scenarioTimeout([]*msgs.Tag{tag("@slow")})
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, timeout)
+
+	timeout, err = suite.scenarioTimeout([]*msgs.Tag{tag("@timeout:30s"), tag("@timeout:1h")})
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, timeout)
+
+	_, err = suite.scenarioTimeout([]*msgs.Tag{tag("@timeout:soon")})
+	require.EqualError(t, err, `the tag @timeout:soon is incorrect: time: invalid duration "soon"`)
+}
+
+func TestSuite_StepTimeout(t *testing.T) {
+	suite := NewSuite(t, WithDefaultStepTimeout(time.Minute))
+	step := &testStep{def: &stepDef{}}
+
+	timeout, scenario := suite.stepTimeout(&testCase{}, step)
+	require.Equal(t, time.Minute, timeout)
+	require.False(t, scenario)
+
+	step.def.timeout = time.Hour
+	timeout, scenario = suite.stepTimeout(&testCase{}, step)
+	require.Equal(t, time.Hour, timeout)
+	require.False(t, scenario)
+
+	timeout, scenario = suite.stepTimeout(&testCase{started: time.Now(), timeout: time.Second}, step)
+	require.True(t, timeout > 0 && timeout <= time.Second, timeout)
+	require.True(t, scenario)
+}